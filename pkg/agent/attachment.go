@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxAttachmentSize caps the size of a single attached file
+const maxAttachmentSize = 1 << 20
+
+// Attachment is a file given to an agent alongside its input
+type Attachment struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ReadAttachment reads a text file to attach to an agent request
+func ReadAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("attachment %s is a directory", path)
+	}
+	if info.Size() > maxAttachmentSize {
+		return Attachment{}, fmt.Errorf("attachment %s is larger than %d KiB", path, maxAttachmentSize>>10)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return Attachment{}, fmt.Errorf("attachment %s is not a text file", path)
+	}
+
+	return Attachment{Path: path, Content: string(data)}, nil
+}
+
+// userMessage combines the request input with its attachments, each
+// wrapped in a <file> element naming its path
+func userMessage(req ExecuteRequest) string {
+	if len(req.Attachments) == 0 {
+		return req.Input
+	}
+
+	var b strings.Builder
+	b.WriteString(req.Input)
+	for _, a := range req.Attachments {
+		fmt.Fprintf(&b, "\n\n<file path=%q>\n%s", a.Path, a.Content)
+		if !strings.HasSuffix(a.Content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("</file>")
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/session"
+)
+
+// defaultKeepRecent is the number of most recent messages never compacted
+const defaultKeepRecent = 6
+
+// Summarizer condenses conversation messages into a short summary
+type Summarizer interface {
+	Summarize(ctx context.Context, model string, messages []session.Message) (string, error)
+}
+
+// Compactor summarises older conversation turns once the estimated
+// token count of a conversation crosses a threshold
+type Compactor struct {
+	Model      string // Model used for summarisation, typically a cheaper one
+	Threshold  int    // Estimated token count that triggers compaction; 0 disables it
+	KeepRecent int    // Number of most recent messages kept verbatim
+	Summarizer Summarizer
+}
+
+// NewCompactor creates a compactor using the given model and threshold
+func NewCompactor(model string, threshold int) *Compactor {
+	return &Compactor{
+		Model:      model,
+		Threshold:  threshold,
+		KeepRecent: defaultKeepRecent,
+		Summarizer: simulatedSummarizer{},
+	}
+}
+
+// EstimateTokens approximates the token count of a piece of text
+func EstimateTokens(text string) int {
+	// Roughly four characters per token for English prose and code
+	return (len(text) + 3) / 4
+}
+
+// EstimateMessageTokens approximates the token count of a conversation
+func EstimateMessageTokens(messages []session.Message) int {
+	total := 0
+	for _, msg := range messages {
+		// Each message carries a few tokens of role and framing overhead
+		total += EstimateTokens(msg.Content) + 4
+	}
+	return total
+}
+
+// Compact summarises older messages when overhead plus the estimated size of
+// messages exceeds the threshold. Tool results that are still referenced by
+// the retained messages are preserved verbatim. The returned bool reports
+// whether compaction took place.
+func (c *Compactor) Compact(ctx context.Context, messages []session.Message, overhead int) ([]session.Message, bool, error) {
+	if c.Threshold <= 0 || overhead+EstimateMessageTokens(messages) <= c.Threshold {
+		return messages, false, nil
+	}
+
+	keep := c.KeepRecent
+	if keep <= 0 {
+		keep = defaultKeepRecent
+	}
+	if len(messages) <= keep {
+		return messages, false, nil
+	}
+
+	older := messages[:len(messages)-keep]
+	recent := messages[len(messages)-keep:]
+
+	// Split older messages into referenced tool results and turns to summarise
+	var preserved, summarise []session.Message
+	for _, msg := range older {
+		if msg.Role == session.RoleTool && isReferenced(msg.ToolCallID, recent) {
+			preserved = append(preserved, msg)
+			continue
+		}
+		summarise = append(summarise, msg)
+	}
+
+	if len(summarise) == 0 {
+		return messages, false, nil
+	}
+
+	summary, err := c.Summarizer.Summarize(ctx, c.Model, summarise)
+	if err != nil {
+		return messages, false, fmt.Errorf("failed to summarise conversation: %w", err)
+	}
+
+	compacted := make([]session.Message, 0, 1+len(preserved)+len(recent))
+	compacted = append(compacted, session.Message{
+		Role:      session.RoleSystem,
+		Content:   fmt.Sprintf("Summary of %d earlier messages:\n%s", len(summarise), summary),
+		Timestamp: time.Now(),
+	})
+	compacted = append(compacted, preserved...)
+	compacted = append(compacted, recent...)
+
+	return compacted, true, nil
+}
+
+// isReferenced reports whether a tool call ID appears in any of the messages,
+// as their tool call ID or as a whole word of their content
+func isReferenced(toolCallID string, messages []session.Message) bool {
+	if toolCallID == "" {
+		return false
+	}
+	for _, msg := range messages {
+		if msg.ToolCallID == toolCallID || containsID(msg.Content, toolCallID) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsID reports whether id occurs in text other than as part of a
+// longer ID, so that call_1 is not found in call_10
+func containsID(text, id string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], id)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(id)
+		if (start == 0 || !isIDByte(text[start-1])) && (end == len(text) || !isIDByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// isIDByte reports whether b can be part of a tool call ID
+func isIDByte(b byte) bool {
+	return b == '_' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// simulatedSummarizer produces an extractive summary of each message.
+// In a real implementation this would prompt the compaction model.
+type simulatedSummarizer struct{}
+
+// Summarize keeps the first line of each message, truncated
+func (simulatedSummarizer) Summarize(ctx context.Context, model string, messages []session.Message) (string, error) {
+	var lines []string
+	for _, msg := range messages {
+		line := strings.TrimSpace(strings.SplitN(msg.Content, "\n", 2)[0])
+		if runes := []rune(line); len(runes) > 120 {
+			line = string(runes[:117]) + "..."
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", msg.Role, line))
+	}
+	return strings.Join(lines, "\n"), nil
+}
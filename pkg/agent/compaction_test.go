@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/session"
+)
+
+func TestCompactor_BelowThreshold(t *testing.T) {
+	compactor := NewCompactor("test-model", 1000)
+	messages := []session.Message{
+		{Role: session.RoleUser, Content: "short question"},
+		{Role: session.RoleAssistant, Content: "short answer"},
+	}
+
+	result, compacted, err := compactor.Compact(context.Background(), messages, 0)
+	if err != nil {
+		t.Fatalf("Compactor.Compact() error = %v", err)
+	}
+	if compacted || len(result) != len(messages) {
+		t.Errorf("Compactor.Compact() compacted = %v, len = %d, want unchanged", compacted, len(result))
+	}
+}
+
+func TestCompactor_PreservesReferencedToolResults(t *testing.T) {
+	compactor := NewCompactor("test-model", 100)
+	compactor.KeepRecent = 2
+
+	long := strings.Repeat("x", 400)
+	messages := []session.Message{
+		{Role: session.RoleUser, Content: "read the config " + long},
+		{Role: session.RoleTool, ToolCallID: "call_1", Content: "config contents " + long},
+		{Role: session.RoleTool, ToolCallID: "call_2", Content: "unrelated listing " + long},
+		{Role: session.RoleAssistant, Content: "done " + long},
+		{Role: session.RoleUser, Content: "now update the value from call_1"},
+		{Role: session.RoleAssistant, Content: "updated"},
+	}
+
+	result, compacted, err := compactor.Compact(context.Background(), messages, 0)
+	if err != nil {
+		t.Fatalf("Compactor.Compact() error = %v", err)
+	}
+	if !compacted {
+		t.Fatalf("Compactor.Compact() compacted = false, want true")
+	}
+
+	if len(result) != 4 {
+		t.Fatalf("Compactor.Compact() = %d messages, want summary + 1 tool result + 2 recent", len(result))
+	}
+	if result[0].Role != session.RoleSystem || !strings.Contains(result[0].Content, "Summary of 3 earlier messages") {
+		t.Errorf("first message = %+v, want summary of 3 messages", result[0])
+	}
+	if result[1].ToolCallID != "call_1" {
+		t.Errorf("preserved tool result = %q, want call_1", result[1].ToolCallID)
+	}
+	if result[3].Content != "updated" {
+		t.Errorf("last message = %q, want most recent turn kept verbatim", result[3].Content)
+	}
+}
+
+func TestIsReferenced_ExactID(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []session.Message
+		want     bool
+	}{
+		{"same ID", []session.Message{{Role: session.RoleTool, ToolCallID: "call_1"}}, true},
+		{"longer ID", []session.Message{{Role: session.RoleTool, ToolCallID: "call_10"}}, false},
+		{"mentioned", []session.Message{{Content: "use the output of call_1."}}, true},
+		{"longer ID mentioned", []session.Message{{Content: "use call_10 and call_1x"}}, false},
+		{"mentioned after a longer ID", []session.Message{{Content: "call_10 replaces call_1"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReferenced("call_1", tt.messages); got != tt.want {
+				t.Errorf("isReferenced(call_1) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactor_PrefixToolCallIDs(t *testing.T) {
+	compactor := NewCompactor("test-model", 100)
+	compactor.KeepRecent = 2
+
+	long := strings.Repeat("x", 400)
+	messages := []session.Message{
+		{Role: session.RoleTool, ToolCallID: "call_1", Content: "first result " + long},
+		{Role: session.RoleTool, ToolCallID: "call_10", Content: "tenth result " + long},
+		{Role: session.RoleAssistant, Content: "done " + long},
+		{Role: session.RoleUser, Content: "use the value from call_10"},
+		{Role: session.RoleAssistant, Content: "updated"},
+	}
+
+	result, compacted, err := compactor.Compact(context.Background(), messages, 0)
+	if err != nil || !compacted {
+		t.Fatalf("Compactor.Compact() = %v, %v, want compaction", compacted, err)
+	}
+	if len(result) != 4 || result[1].ToolCallID != "call_10" {
+		t.Errorf("Compactor.Compact() = %+v, want summary, call_10 and 2 recent messages", result)
+	}
+}
+
+func TestCompactor_Disabled(t *testing.T) {
+	compactor := NewCompactor("test-model", 0)
+	messages := []session.Message{
+		{Role: session.RoleUser, Content: strings.Repeat("x", 10000)},
+	}
+
+	_, compacted, err := compactor.Compact(context.Background(), messages, 0)
+	if err != nil || compacted {
+		t.Errorf("Compactor.Compact() with threshold 0 = %v, %v, want no compaction", compacted, err)
+	}
+}
+
+func TestSimulatedSummarizer_TruncatesOnRunes(t *testing.T) {
+	messages := []session.Message{
+		{Role: session.RoleUser, Content: "a" + strings.Repeat("é", 200)},
+		{Role: session.RoleAssistant, Content: strings.Repeat("日本語", 50) + "\nsecond line"},
+	}
+
+	summary, err := simulatedSummarizer{}.Summarize(context.Background(), "test-model", messages)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if !utf8.ValidString(summary) {
+		t.Errorf("Summarize() = %q, want valid UTF-8", summary)
+	}
+	for _, line := range strings.Split(summary, "\n") {
+		_, text, _ := strings.Cut(line, ": ")
+		if n := utf8.RuneCountInString(text); n != 120 || !strings.HasSuffix(text, "...") {
+			t.Errorf("summary line %q has %d characters, want 117 and \"...\"", line, n)
+		}
+	}
+}
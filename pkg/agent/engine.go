@@ -2,36 +2,95 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/project"
 	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/gsmlg-dev/open-code-agents/pkg/session"
 )
 
 // Engine handles agent execution and management
 type Engine struct {
 	workingDir string
 	context    map[string]interface{}
+	sessions   *session.Store
+	maxHistory int
+	compactor  *Compactor
+	resolution []string          // Agent sources, highest precedence first
+	promptVars map[string]string // Template variables from user and project config
 }
 
-// NewEngine creates a new agent engine
+// NewEngine creates a new agent engine configured from the effective
+// configuration, or from the defaults if a config file cannot be loaded.
+// Use LoadEngine to get the error instead.
 func NewEngine() *Engine {
+	engine, err := LoadEngine()
+	if err != nil {
+		return newEngine(config.DefaultConfig())
+	}
+	return engine
+}
+
+// LoadEngine creates a new agent engine configured from the effective
+// configuration. It returns an error if a config file cannot be loaded.
+func LoadEngine() (*Engine, error) {
+	// Defaults, user and project config and OPENCODE_* variables, merged
+	effective, err := config.LoadEffective()
+	if err != nil {
+		return nil, err
+	}
+	return newEngine(effective.Config), nil
+}
+
+// newEngine creates an agent engine with the given configuration
+func newEngine(cfg *config.Config) *Engine {
 	wd, _ := os.Getwd()
+
+	settings := cfg.Settings
+	promptVars := cfg.PromptVars
+	if promptVars == nil {
+		promptVars = make(map[string]string)
+	}
+
+	resolution := settings.AgentResolution
+	if config.ValidateAgentResolution(resolution) != nil {
+		resolution = config.DefaultAgentResolution()
+	}
+
+	// Sessions are unavailable if the project directory cannot be resolved
+	sessions, _ := session.DefaultStore(config.ProjectScope)
+
 	return &Engine{
 		workingDir: wd,
 		context:    make(map[string]interface{}),
+		sessions:   sessions,
+		maxHistory: settings.MaxHistory,
+		compactor:  NewCompactor(settings.CompactionModel, settings.CompactionThreshold),
+		resolution: resolution,
+		promptVars: promptVars,
 	}
 }
 
+// Sessions returns the store used to persist conversation sessions
+func (e *Engine) Sessions() *session.Store {
+	return e.sessions
+}
+
 // ExecuteRequest represents an agent execution request
 type ExecuteRequest struct {
 	AgentName string                 `json:"agent_name"`
 	Input     string                 `json:"input"`
 	Context   map[string]interface{} `json:"context,omitempty"`
 	Tools     map[string]bool        `json:"tools,omitempty"`
+	Session   string                 `json:"session,omitempty"`
+	// Attachments are files sent to the agent with the input
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Stream, if set, receives the output in chunks as it is produced
+	Stream func(chunk string) `json:"-"`
 }
 
 // ExecuteResponse represents the result of agent execution
@@ -41,6 +100,16 @@ type ExecuteResponse struct {
 	Error   string                 `json:"error,omitempty"`
 	Context map[string]interface{} `json:"context,omitempty"`
 	Handoff *HandoffSuggestion     `json:"handoff,omitempty"`
+	Session string                 `json:"session,omitempty"`
+	// Compacted reports whether older session turns were summarised before execution
+	Compacted bool   `json:"compacted,omitempty"`
+	Usage     *Usage `json:"usage,omitempty"`
+}
+
+// Usage reports the estimated tokens sent to and received from the model
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 // HandoffSuggestion suggests next agent to use
@@ -61,128 +130,151 @@ func (e *Engine) Execute(ctx context.Context, req ExecuteRequest) (*ExecuteRespo
 		}, nil
 	}
 
+	// Load conversation history when continuing a session
+	var sess *session.Session
+	if req.Session != "" {
+		sess, err = e.loadSession(req.Session, agent.Name)
+		if err != nil {
+			return &ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to load session: %v", err),
+			}, nil
+		}
+	}
+
+	// Assemble the system prompt, including any project context the agent opted into
+	p := prompt{
+		System:      e.buildSystemPrompt(agent),
+		User:        userMessage(req),
+		Attachments: req.Attachments,
+	}
+
+	// Summarise older turns if the conversation no longer fits the context budget
+	compacted := false
+	if sess != nil {
+		overhead := EstimateTokens(p.System) + EstimateTokens(p.User)
+		sess.Messages, compacted, err = e.compactor.Compact(ctx, sess.Messages, overhead)
+		if err != nil {
+			return &ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to compact session: %v", err),
+			}, nil
+		}
+	}
+
 	// Prepare execution context
-	execCtx := e.prepareContext(req.Context)
+	var sessionCtx map[string]interface{}
+	if sess != nil {
+		sessionCtx = sess.Context
+		p.History = sess.Messages
+	}
+	execCtx := e.prepareContext(sessionCtx, req.Context)
 
 	// Execute agent based on its type
+	var resp *ExecuteResponse
 	switch agent.Mode {
-	case "primary":
-		return e.executePrimaryAgent(ctx, agent, req, execCtx)
-	case "subagent":
-		return e.executeSubAgent(ctx, agent, req, execCtx)
+	case resources.ModePrimary:
+		resp, err = e.executePrimaryAgent(ctx, agent, req, execCtx, p)
+	case resources.ModeSubagent:
+		resp, err = e.executeSubAgent(ctx, agent, req, execCtx, p)
 	default:
 		return &ExecuteResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Unknown agent mode: %s", agent.Mode),
 		}, nil
 	}
-}
-
-// loadAgent loads agent from installed locations or embedded resources
-func (e *Engine) loadAgent(name string) (*resources.AgentResource, error) {
-	// Try to load from user scope first
-	if agent, err := e.loadInstalledAgent(name, config.UserScope); err == nil {
-		return &agent, nil
+	if err != nil || !resp.Success {
+		return resp, err
 	}
 
-	// Try project scope
-	if agent, err := e.loadInstalledAgent(name, config.ProjectScope); err == nil {
-		return &agent, nil
+	resp.Usage = &Usage{
+		InputTokens:  EstimateTokens(p.System) + EstimateMessageTokens(p.History) + EstimateTokens(p.User),
+		OutputTokens: EstimateTokens(resp.Output),
 	}
 
-	// Fall back to embedded resources
-	agent, err := resources.GetAgent(name)
-	if err != nil {
-		return nil, fmt.Errorf("agent '%s' not found in any location", name)
+	// The simulated model answers at once, so its output is streamed by line
+	if req.Stream != nil {
+		for _, chunk := range strings.SplitAfter(resp.Output, "\n") {
+			if chunk != "" {
+				req.Stream(chunk)
+			}
+		}
 	}
 
-	return &agent, nil
-}
-
-// loadInstalledAgent loads agent from filesystem
-func (e *Engine) loadInstalledAgent(name string, scope config.Scope) (resources.AgentResource, error) {
-	agentDir, err := config.GetAgentDir(scope)
-	if err != nil {
-		return resources.AgentResource{}, err
+	if sess == nil {
+		return resp, nil
 	}
 
-	agentFile := filepath.Join(agentDir, name+".md")
-	content, err := os.ReadFile(agentFile)
-	if err != nil {
-		return resources.AgentResource{}, err
+	// Record the exchange and persist the session
+	sess.Append(session.RoleUser, p.User)
+	sess.Append(session.RoleAssistant, resp.Output)
+	sess.Trim(e.maxHistory)
+	for k, v := range resp.Context {
+		sess.Context[k] = v
 	}
+	if err := e.sessions.Save(sess); err != nil {
+		return &ExecuteResponse{
+			Output:  resp.Output,
+			Success: false,
+			Error:   fmt.Sprintf("Failed to save session: %v", err),
+			Context: resp.Context,
+			Usage:   resp.Usage,
+		}, nil
+	}
+	resp.Session = sess.Name
+	resp.Compacted = compacted
 
-	// Parse frontmatter and content
-	return e.parseInstalledAgent(name, string(content))
+	return resp, nil
 }
 
-// parseInstalledAgent parses installed agent file with frontmatter
-func (e *Engine) parseInstalledAgent(name, content string) (resources.AgentResource, error) {
-	lines := strings.Split(content, "\n")
-
-	// Find frontmatter boundaries
-	frontmatterStart := -1
-	frontmatterEnd := -1
-
-	for i, line := range lines {
-		if line == "---" {
-			if frontmatterStart == -1 {
-				frontmatterStart = i
-			} else {
-				frontmatterEnd = i
-				break
-			}
-		}
-	}
+// prompt is the model input assembled for an agent execution
+type prompt struct {
+	System      string            // Agent instructions with any injected project context
+	History     []session.Message // Earlier turns of the session
+	User        string            // Input followed by any attached files
+	Attachments []Attachment
+}
 
-	agent := resources.AgentResource{
-		Name:  name,
-		Tools: make(map[string]bool),
+// buildSystemPrompt prepends the agent's opted-in project context to its instructions
+func (e *Engine) buildSystemPrompt(agent *resources.AgentResource) string {
+	if len(agent.ProjectContext) == 0 {
+		return agent.Content
 	}
 
-	if frontmatterStart != -1 && frontmatterEnd != -1 {
-		// Parse frontmatter
-		frontmatter := strings.Join(lines[frontmatterStart+1:frontmatterEnd], "\n")
-		e.parseFrontmatter(frontmatter, &agent)
-
-		// Extract content after frontmatter
-		agent.Content = strings.Join(lines[frontmatterEnd+1:], "\n")
-	} else {
-		// No frontmatter, treat entire file as content
-		agent.Content = content
-		agent.Mode = "subagent"
-		agent.Temperature = 0.3
+	projectCtx := project.Gather(e.workingDir, project.Options{
+		Sources:     agent.ProjectContext,
+		TokenBudget: agent.ContextBudget,
+	})
+	if projectCtx == "" {
+		return agent.Content
 	}
 
-	return agent, nil
+	return projectCtx + "\n" + agent.Content
 }
 
-// parseFrontmatter parses YAML frontmatter
-func (e *Engine) parseFrontmatter(frontmatter string, agent *resources.AgentResource) {
-	lines := strings.Split(frontmatter, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// loadSession loads an existing session or starts a new one for the agent
+func (e *Engine) loadSession(name, agentName string) (*session.Session, error) {
+	if e.sessions == nil {
+		return nil, fmt.Errorf("session storage is not available")
+	}
 
-		if strings.HasPrefix(line, "description:") {
-			agent.Description = strings.TrimSpace(strings.TrimPrefix(line, "description:"))
-		} else if strings.HasPrefix(line, "mode:") {
-			agent.Mode = strings.TrimSpace(strings.TrimPrefix(line, "mode:"))
-		} else if strings.HasPrefix(line, "temperature:") {
-			var temp float64
-			fmt.Sscanf(strings.TrimSpace(strings.TrimPrefix(line, "temperature:")), "%f", &temp)
-			agent.Temperature = temp
-		} else if strings.HasPrefix(line, "tools:") {
-			// Tools parsing would be more complex in real implementation
-			agent.Tools["read"] = true
-		}
+	sess, err := e.sessions.Load(name)
+	if errors.Is(err, session.ErrNotFound) {
+		return session.New(name, agentName), nil
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sess.AgentName != agentName {
+		return nil, fmt.Errorf("session '%s' belongs to agent '%s'", name, sess.AgentName)
+	}
+
+	return sess, nil
 }
 
 // prepareContext prepares execution context
-func (e *Engine) prepareContext(reqContext map[string]interface{}) map[string]interface{} {
+func (e *Engine) prepareContext(sessionContext, reqContext map[string]interface{}) map[string]interface{} {
 	ctx := make(map[string]interface{})
 
 	// Copy engine context
@@ -190,6 +282,11 @@ func (e *Engine) prepareContext(reqContext map[string]interface{}) map[string]in
 		ctx[k] = v
 	}
 
+	// Layer context carried over from earlier turns of the session
+	for k, v := range sessionContext {
+		ctx[k] = v
+	}
+
 	// Override with request context
 	for k, v := range reqContext {
 		ctx[k] = v
@@ -202,17 +299,18 @@ func (e *Engine) prepareContext(reqContext map[string]interface{}) map[string]in
 }
 
 // executePrimaryAgent executes a primary agent (can call other agents)
-func (e *Engine) executePrimaryAgent(ctx context.Context, agent *resources.AgentResource, req ExecuteRequest, execCtx map[string]interface{}) (*ExecuteResponse, error) {
+func (e *Engine) executePrimaryAgent(ctx context.Context, agent *resources.AgentResource, req ExecuteRequest, execCtx map[string]interface{}, p prompt) (*ExecuteResponse, error) {
 	// In a real implementation, this would:
 	// 1. Set up the AI model with the agent's configuration
 	// 2. Provide the agent with its tools and context
-	// 3. Execute the agent's prompt with the user input
+	// 3. Execute the agent's prompt with the conversation history and user input
 	// 4. Parse the response and handle any handoffs
 
 	// For now, simulate execution
 	output := fmt.Sprintf("Executed %s agent with input: %s\n", agent.Name, req.Input)
 	output += fmt.Sprintf("Mode: %s, Temperature: %.1f\n", agent.Mode, agent.Temperature)
 	output += fmt.Sprintf("Available tools: %v\n", agent.Tools)
+	output += describePrompt(agent, p)
 
 	// Simulate potential handoff based on agent type
 	var handoff *HandoffSuggestion
@@ -240,11 +338,12 @@ func (e *Engine) executePrimaryAgent(ctx context.Context, agent *resources.Agent
 }
 
 // executeSubAgent executes a subagent (focused task)
-func (e *Engine) executeSubAgent(ctx context.Context, agent *resources.AgentResource, req ExecuteRequest, execCtx map[string]interface{}) (*ExecuteResponse, error) {
+func (e *Engine) executeSubAgent(ctx context.Context, agent *resources.AgentResource, req ExecuteRequest, execCtx map[string]interface{}, p prompt) (*ExecuteResponse, error) {
 	// Similar to primary agent but without handoff capabilities
 	output := fmt.Sprintf("Executed %s subagent with input: %s\n", agent.Name, req.Input)
 	output += fmt.Sprintf("Mode: %s, Temperature: %.1f\n", agent.Mode, agent.Temperature)
 	output += fmt.Sprintf("Available tools: %v\n", agent.Tools)
+	output += describePrompt(agent, p)
 
 	return &ExecuteResponse{
 		Output:  output,
@@ -253,9 +352,91 @@ func (e *Engine) executeSubAgent(ctx context.Context, agent *resources.AgentReso
 	}, nil
 }
 
-// ListAvailableAgents returns all available agents
+// describePrompt summarises the assembled prompt for simulated output
+func describePrompt(agent *resources.AgentResource, p prompt) string {
+	var desc string
+	if len(agent.ProjectContext) > 0 {
+		desc += fmt.Sprintf("Project context: %s (system prompt ~%d tokens)\n",
+			strings.Join(agent.ProjectContext, ", "), EstimateTokens(p.System))
+	}
+	if len(p.History) > 0 {
+		desc += fmt.Sprintf("Conversation history: %d messages\n", len(p.History))
+	}
+	if len(p.Attachments) > 0 {
+		paths := make([]string, len(p.Attachments))
+		for i, a := range p.Attachments {
+			paths[i] = a.Path
+		}
+		desc += fmt.Sprintf("Attachments: %s (input ~%d tokens)\n", strings.Join(paths, ", "), EstimateTokens(p.User))
+	}
+	return desc
+}
+
+// ListAvailableAgents returns the built-in agents followed by custom agents
+// installed in either scope, with overrides and inheritance applied. Agents
+// that fail to load are left out; use ListAvailableAgentsWithErrors to
+// report them.
 func (e *Engine) ListAvailableAgents() ([]resources.AgentResource, error) {
-	return resources.GetAvailableAgents()
+	agents, _, err := e.ListAvailableAgentsWithErrors()
+	return agents, err
+}
+
+// ListAvailableAgentsWithErrors returns the agents like ListAvailableAgents.
+// Agents that fail to load, such as files that do not parse or extend a
+// missing agent, are left out and their errors returned in skipped, so one
+// broken file does not hide the others.
+func (e *Engine) ListAvailableAgentsWithErrors() (agents []resources.AgentResource, skipped []error, err error) {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, source := range e.resolution {
+		if source != config.SourceEmbedded {
+			continue
+		}
+		embedded, err := resources.GetAvailableAgents()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, agent := range embedded {
+			add(agent.Name)
+		}
+	}
+
+	for _, source := range e.resolution {
+		if source == config.SourceEmbedded {
+			continue
+		}
+		agentDir, err := config.GetAgentDir(config.Scope(source))
+		if err != nil {
+			continue
+		}
+
+		installed, err := resources.AgentNamesInDir(agentDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s agents: %w", source, err)
+		}
+		for _, name := range installed {
+			add(name)
+		}
+	}
+
+	agents = make([]resources.AgentResource, 0, len(names))
+	for _, name := range names {
+		agent, err := e.loadAgent(name)
+		if err != nil {
+			skipped = append(skipped, err)
+			continue
+		}
+		agents = append(agents, *agent)
+	}
+
+	return agents, skipped, nil
 }
 
 // ListInstalledAgents returns all installed agents
@@ -2,11 +2,37 @@ package agent
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/gsmlg-dev/open-code-agents/pkg/session"
 )
 
+func TestLoadEngine_InvalidConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir, err := config.GetConfigPath(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"settings": {"max_history": "ten"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if engine, err := LoadEngine(); err == nil {
+		t.Errorf("LoadEngine() = %+v, want an error for the invalid config file", engine)
+	}
+	if engine := NewEngine(); engine.maxHistory != config.DefaultConfig().Settings.MaxHistory {
+		t.Errorf("NewEngine() max history = %d, want the default", engine.maxHistory)
+	}
+}
+
 func TestEngine_LoadAgent(t *testing.T) {
 	engine := NewEngine()
 
@@ -57,6 +83,100 @@ func TestEngine_Execute(t *testing.T) {
 	}
 }
 
+func TestEngine_ExecuteSession(t *testing.T) {
+	engine := NewEngine()
+	engine.sessions = session.NewStore(t.TempDir())
+	engine.maxHistory = 3
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		resp, err := engine.Execute(ctx, ExecuteRequest{
+			AgentName: "implementer",
+			Input:     "Continue the work",
+			Session:   "feature",
+		})
+		if err != nil || !resp.Success {
+			t.Fatalf("Engine.Execute() turn %d = %+v, %v", i+1, resp, err)
+		}
+		if resp.Session != "feature" {
+			t.Errorf("Engine.Execute() session = %q, want feature", resp.Session)
+		}
+	}
+
+	sess, err := engine.sessions.Load("feature")
+	if err != nil {
+		t.Fatalf("session not persisted: %v", err)
+	}
+	if len(sess.Messages) != 3 {
+		t.Errorf("session history = %d messages, want trimmed to 3", len(sess.Messages))
+	}
+	if last := sess.Messages[len(sess.Messages)-1]; !strings.Contains(last.Content, "Conversation history: 2 messages") {
+		t.Errorf("second turn did not see prior history, output = %q", last.Content)
+	}
+
+	// A session cannot be continued by a different agent
+	resp, err := engine.Execute(ctx, ExecuteRequest{
+		AgentName: "architect",
+		Input:     "Design it",
+		Session:   "feature",
+	})
+	if err != nil {
+		t.Fatalf("Engine.Execute() error = %v", err)
+	}
+	if resp.Success {
+		t.Errorf("Engine.Execute() with another agent's session succeeded, want failure")
+	}
+}
+
+func TestEngine_ExecuteAttachments(t *testing.T) {
+	engine := NewEngine()
+	engine.sessions = session.NewStore(t.TempDir())
+
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("retry on 503"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "blob.bin")
+	if err := os.WriteFile(binary, []byte{0x7f, 0x00, 0x01}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadAttachment(binary); err == nil {
+		t.Errorf("ReadAttachment() of binary file error = nil, want error")
+	}
+	if _, err := ReadAttachment(dir); err == nil {
+		t.Errorf("ReadAttachment() of directory error = nil, want error")
+	}
+
+	attachment, err := ReadAttachment(notes)
+	if err != nil {
+		t.Fatalf("ReadAttachment() error = %v", err)
+	}
+
+	resp, err := engine.Execute(context.Background(), ExecuteRequest{
+		AgentName:   "reviewer",
+		Input:       "Review the retry logic\nacross two lines",
+		Session:     "review",
+		Attachments: []Attachment{attachment},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Engine.Execute() = %+v, %v", resp, err)
+	}
+	if !strings.Contains(resp.Output, "Attachments: "+notes) {
+		t.Errorf("output does not list attachment, got %q", resp.Output)
+	}
+
+	sess, err := engine.sessions.Load("review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Review the retry logic\nacross two lines\n\n<file path=\"" + notes + "\">\nretry on 503\n</file>"
+	if got := sess.Messages[0].Content; got != want {
+		t.Errorf("session user message = %q, want %q", got, want)
+	}
+}
+
 func TestEngine_ListAvailableAgents(t *testing.T) {
 	engine := NewEngine()
 
@@ -84,6 +204,45 @@ func TestEngine_ListAvailableAgents(t *testing.T) {
 	}
 }
 
+func TestEngine_ListAvailableAgentsSkipsBroken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"custom.md":   "---\ndescription: Custom agent\nmode: subagent\n---\n\n## Role\nHelps.\n",
+		"broken.md":   "---\ndescription: [unclosed\n---\n",
+		"orphan-x.md": "---\nextends: missing-parent\n---\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(agentDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	agents, skipped, err := NewEngine().ListAvailableAgentsWithErrors()
+	if err != nil {
+		t.Fatalf("Engine.ListAvailableAgentsWithErrors() error = %v", err)
+	}
+	names := make(map[string]bool)
+	for _, agent := range agents {
+		names[agent.Name] = true
+	}
+	if !names["custom"] || !names["implementer"] || names["broken"] || names["orphan-x"] {
+		t.Errorf("Engine.ListAvailableAgentsWithErrors() = %v, want custom and built-ins without broken agents", names)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("skipped = %v, want errors for broken and orphan-x", skipped)
+	}
+	if listed, err := NewEngine().ListAvailableAgents(); err != nil || len(listed) != len(agents) {
+		t.Errorf("Engine.ListAvailableAgents() = %d agents, %v, want %d without an error", len(listed), err, len(agents))
+	}
+}
+
 func TestEngine_ListInstalledAgents(t *testing.T) {
 	engine := NewEngine()
 
@@ -102,3 +261,227 @@ func TestEngine_ListInstalledAgents(t *testing.T) {
 		t.Errorf("Engine.ListInstalledAgents() missing project scope")
 	}
 }
+
+func TestEngine_ProjectContext(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("Use table-driven tests."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine()
+	engine.workingDir = dir
+
+	agent, err := resources.ParseAgent("custom", "---\ndescription: Custom agent\nmode: subagent\nproject_context: [docs, git]\ncontext_budget: 500\n---\n\n# Custom Agent\n")
+	if err != nil {
+		t.Fatalf("ParseAgent() error = %v", err)
+	}
+	if strings.Join(agent.ProjectContext, ",") != "docs,git" || agent.ContextBudget != 500 {
+		t.Errorf("parsed project context = %v (budget %d), want [docs git] (budget 500)", agent.ProjectContext, agent.ContextBudget)
+	}
+
+	system := engine.buildSystemPrompt(&agent)
+	if !strings.Contains(system, "Use table-driven tests.") || !strings.HasSuffix(system, "# Custom Agent\n") {
+		t.Errorf("Engine.buildSystemPrompt() = %q, want project docs before agent content", system)
+	}
+}
+
+func TestEngine_LoadAgentExtends(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"go-implementer.md": "---\nextends: implementer\nmodel: anthropic/claude-sonnet-4-20250514\nsections:\n  Role: append\n---\n\n## Role\nFollow the Go style guide.\n",
+		"loop-a.md":         "---\nextends: loop-b\n---\n",
+		"loop-b.md":         "---\nextends: loop-a\n---\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(agentDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine := NewEngine()
+
+	agent, err := engine.loadAgent("go-implementer")
+	if err != nil {
+		t.Fatalf("Engine.loadAgent() error = %v", err)
+	}
+	if agent.Model != "anthropic/claude-sonnet-4-20250514" || agent.Mode != resources.ModePrimary {
+		t.Errorf("extended agent model/mode = %s/%s, want override model and inherited primary mode", agent.Model, agent.Mode)
+	}
+	if !strings.Contains(agent.Content, "Follow the Go style guide.") || !strings.Contains(agent.Content, "## Responsibilities") {
+		t.Errorf("extended agent content missing appended or inherited sections:\n%s", agent.Content)
+	}
+
+	if _, err := engine.loadAgent("loop-a"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Engine.loadAgent() error = %v, want inheritance cycle error", err)
+	}
+}
+
+func TestEngine_ResolveAgentPrecedence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(projectDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	files := map[config.Scope]map[string]string{
+		config.UserScope: {
+			"reviewer.md": "---\ndescription: User reviewer\nmode: subagent\n---\n\n## Role\nReviews code.\n",
+		},
+		config.ProjectScope: {
+			"reviewer.md": "---\ndescription: Project reviewer\nmode: subagent\n---\n\n## Role\nReviews this project.\n",
+			"tester.md":   "---\noverride: true\ntemperature: 0.1\n---\n",
+			"orphan.md":   "---\noverride: true\n---\n",
+		},
+	}
+	for scope, agents := range files {
+		agentDir, err := config.GetAgentDir(scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(agentDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range agents {
+			if err := os.WriteFile(filepath.Join(agentDir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		resolution []string
+		agent      string
+		wantSource string
+		wantDesc   string
+	}{
+		{"project wins by default", config.DefaultAgentResolution(), "reviewer", "project", "Project reviewer"},
+		{"user first when configured", []string{"user", "project", "embedded"}, "reviewer", "user", "User reviewer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine()
+			engine.resolution = tt.resolution
+
+			res, err := engine.ResolveAgent(tt.agent)
+			if err != nil {
+				t.Fatalf("Engine.ResolveAgent() error = %v", err)
+			}
+			if got := res.Winner().Source; got != tt.wantSource {
+				t.Errorf("Engine.ResolveAgent() winner = %s, want %s", got, tt.wantSource)
+			}
+			if res.Agent.Description != tt.wantDesc {
+				t.Errorf("Engine.ResolveAgent() description = %q, want %q", res.Agent.Description, tt.wantDesc)
+			}
+		})
+	}
+
+	engine := NewEngine()
+	engine.resolution = config.DefaultAgentResolution()
+
+	// An override file patches the embedded tester without replacing its content
+	res, err := engine.ResolveAgent("tester")
+	if err != nil {
+		t.Fatalf("Engine.ResolveAgent() error = %v", err)
+	}
+	if res.Agent.Temperature != 0.1 || !strings.Contains(res.Agent.Content, "## Role") {
+		t.Errorf("overridden tester = temperature %.1f, content %q", res.Agent.Temperature, res.Agent.Content)
+	}
+	if len(res.Layers) != 2 || res.Layers[0].Source != config.SourceEmbedded || !res.Layers[1].Override {
+		t.Errorf("overridden tester layers = %+v, want embedded then project override", res.Layers)
+	}
+
+	if _, err := engine.ResolveAgent("orphan"); err == nil {
+		t.Errorf("Engine.ResolveAgent() with nothing to override succeeded, want error")
+	}
+}
+
+func TestEngine_LoadAgentTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(agentDir, "shared"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"shared/go-style.md": "## Style\n- Follow the {{.Vars.team}} team's error conventions\n",
+		"go-implementer.md":  "---\nextends: implementer\n---\n\n# {{.ProjectName}} Implementer\n\nWrites {{.Language}} code.\n\n{{include \"shared/go-style.md\"}}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(agentDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module example.com/widgets\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine()
+	engine.workingDir = projectDir
+	engine.promptVars = map[string]string{"team": "platform"}
+
+	agent, err := engine.loadAgent("go-implementer")
+	if err != nil {
+		t.Fatalf("Engine.loadAgent() error = %v", err)
+	}
+	for _, want := range []string{"# widgets Implementer\n\nWrites Go code.", "## Style\n- Follow the platform team's error conventions\n", "## Role"} {
+		if !strings.Contains(agent.Content, want) {
+			t.Errorf("expanded content missing %q:\n%s", want, agent.Content)
+		}
+	}
+}
+
+func TestEngine_LoadAgentLiteralBraces(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\ndescription: Helm charts\n---\n\n## Role\nWrites charts. Set `{{ .Values.x }}` in values.yaml.\n"
+	if err := os.WriteFile(filepath.Join(agentDir, "helm.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine()
+	agent, err := engine.loadAgent("helm")
+	if err != nil {
+		t.Fatalf("Engine.loadAgent() error = %v", err)
+	}
+	if !strings.Contains(agent.Content, "`{{ .Values.x }}`") {
+		t.Errorf("content = %q, want the literal braces kept", agent.Content)
+	}
+
+	agents, skipped, err := engine.ListAvailableAgentsWithErrors()
+	if err != nil || len(skipped) != 0 {
+		t.Fatalf("Engine.ListAvailableAgentsWithErrors() skipped = %v, error = %v", skipped, err)
+	}
+	found := false
+	for _, a := range agents {
+		found = found || a.Name == "helm"
+	}
+	if !found {
+		t.Error("Engine.ListAvailableAgentsWithErrors() left out the helm agent")
+	}
+}
@@ -0,0 +1,200 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/gsmlg-dev/open-code-agents/pkg/tmpl"
+)
+
+// Layer is one agent file that contributed to a resolved agent
+type Layer struct {
+	Agent    string `json:"agent"`    // Name of the agent the file defines
+	Source   string `json:"source"`   // "project", "user" or "embedded"
+	Path     string `json:"path"`     // File path, relative to the binary for embedded agents
+	Override bool   `json:"override"` // Whether the file patches a lower-precedence definition
+}
+
+// Resolution describes how an agent was assembled from its files
+type Resolution struct {
+	Agent  resources.AgentResource `json:"agent"`
+	Order  []string                `json:"order"`  // Source precedence, highest first
+	Layers []Layer                 `json:"layers"` // Files in the order they were applied
+}
+
+// Winner returns the highest-precedence file defining the agent itself
+func (r *Resolution) Winner() Layer {
+	for i := len(r.Layers) - 1; i >= 0; i-- {
+		if r.Layers[i].Agent == r.Agent.Name {
+			return r.Layers[i]
+		}
+	}
+	return Layer{}
+}
+
+// foundDefinition is an agent definition and the file it came from
+type foundDefinition struct {
+	def   resources.Definition
+	layer Layer
+}
+
+// ResolveAgent finds an agent in the configured sources, applying the
+// agents it extends and any override files, and reports which files were used
+func (e *Engine) ResolveAgent(name string) (*Resolution, error) {
+	def, layers, err := e.resolveDefinition(name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	agent := def.Agent()
+	if agent.Content, err = e.expandContent(agent.Content); err != nil {
+		return nil, fmt.Errorf("agent '%s': %w", name, err)
+	}
+
+	return &Resolution{
+		Agent:  agent,
+		Order:  e.resolution,
+		Layers: layers,
+	}, nil
+}
+
+// loadAgent loads agent from installed locations or embedded resources,
+// resolving any agents it extends
+func (e *Engine) loadAgent(name string) (*resources.AgentResource, error) {
+	res, err := e.ResolveAgent(name)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Agent, nil
+}
+
+// resolveDefinition loads a definition, merges it over the chain of agents
+// it extends and applies override files from lowest to highest precedence
+func (e *Engine) resolveDefinition(name string, chain []string) (resources.Definition, []Layer, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return resources.Definition{}, nil, fmt.Errorf("agent inheritance cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	found, err := e.findDefinitions(name)
+	if err != nil {
+		return resources.Definition{}, nil, err
+	}
+
+	// The last definition found is the full one; any before it are overrides
+	base := found[len(found)-1]
+	def := base.def
+	var layers []Layer
+
+	if parentName := def.Frontmatter.Extends; parentName != "" {
+		parent, parentLayers, err := e.resolveDefinition(parentName, append(chain, name))
+		if err != nil {
+			return resources.Definition{}, nil, fmt.Errorf("agent '%s' extends '%s': %w", name, parentName, err)
+		}
+		if def, err = resources.Merge(parent, def); err != nil {
+			return resources.Definition{}, nil, err
+		}
+		layers = parentLayers
+	}
+	layers = append(layers, base.layer)
+
+	for i := len(found) - 2; i >= 0; i-- {
+		if def, err = resources.Merge(def, found[i].def); err != nil {
+			return resources.Definition{}, nil, err
+		}
+		layers = append(layers, found[i].layer)
+	}
+
+	return def, layers, nil
+}
+
+// findDefinitions returns the definitions of an agent in resolution order,
+// stopping at the first one that is not an override file
+func (e *Engine) findDefinitions(name string) ([]foundDefinition, error) {
+	var found []foundDefinition
+	for _, source := range e.resolution {
+		f, ok, err := e.loadFromSource(name, source)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		found = append(found, f)
+		if !f.layer.Override {
+			return found, nil
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("agent '%s' not found in any location", name)
+	}
+	return nil, fmt.Errorf("%s: override of agent '%s' has no lower-precedence definition to patch", found[len(found)-1].layer.Path, name)
+}
+
+// loadFromSource loads an agent definition from one source without
+// resolving inheritance. It reports false if the source has no such agent.
+func (e *Engine) loadFromSource(name, source string) (foundDefinition, bool, error) {
+	if source == config.SourceEmbedded {
+		def, err := resources.GetDefinition(name)
+		if err != nil {
+			return foundDefinition{}, false, nil
+		}
+		layer := Layer{Agent: name, Source: source, Path: "embed/agents/" + name + ".md"}
+		return foundDefinition{def: def, layer: layer}, true, nil
+	}
+
+	agentDir, err := config.GetAgentDir(config.Scope(source))
+	if err != nil {
+		return foundDefinition{}, false, nil
+	}
+
+	agentFile := filepath.Join(agentDir, name+".md")
+	content, err := os.ReadFile(agentFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return foundDefinition{}, false, nil
+	}
+	if err != nil {
+		return foundDefinition{}, false, fmt.Errorf("failed to read agent file: %w", err)
+	}
+
+	// A malformed installed agent is reported rather than silently shadowed
+	def, err := resources.ParseDefinition(name, string(content))
+	if err != nil {
+		return foundDefinition{}, false, fmt.Errorf("%s: %w", agentFile, err)
+	}
+	if def.Frontmatter.Override && def.Frontmatter.Extends != "" {
+		return foundDefinition{}, false, fmt.Errorf("%s: an override file cannot also extend another agent", agentFile)
+	}
+
+	layer := Layer{Agent: name, Source: source, Path: agentFile, Override: def.Frontmatter.Override}
+	return foundDefinition{def: def, layer: layer}, true, nil
+}
+
+// expandContent resolves template variables and includes in agent content.
+// Included files are looked up in the installed agent directories in
+// resolution order.
+func (e *Engine) expandContent(content string) (string, error) {
+	if !tmpl.HasDirectives(content) {
+		return content, nil
+	}
+
+	var dirs []string
+	for _, source := range e.resolution {
+		if source == config.SourceEmbedded {
+			continue
+		}
+		if agentDir, err := config.GetAgentDir(config.Scope(source)); err == nil {
+			dirs = append(dirs, agentDir)
+		}
+	}
+
+	return tmpl.Expand(content, tmpl.ProjectVars(e.workingDir, e.promptVars), tmpl.DirIncluder(dirs...))
+}
@@ -2,11 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/config"
 	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
 	"github.com/gsmlg-dev/open-code-agents/pkg/interactive"
 	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/gsmlg-dev/open-code-agents/pkg/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -16,17 +18,33 @@ func NewAgentCommand() *cobra.Command {
 		Use:   "agents",
 		Short: "Manage OpenCode agents",
 		Long: `Interactive menu for managing OpenCode agents.
-Options include installing all agents, selecting specific agents, or viewing current installations.`,
+Options include installing all agents, selecting specific agents, viewing current installations,
+or uninstalling agents. In a terminal the agents are shown in a full-screen list with their
+install status per scope; otherwise a numbered menu is read from stdin.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			runAgentMenu()
 		},
 	}
 
+	cmd.AddCommand(NewAgentInstallCommand())
+	cmd.AddCommand(NewAgentListCommand())
+	cmd.AddCommand(NewAgentShowCommand())
+	cmd.AddCommand(NewAgentLintCommand())
+	cmd.AddCommand(NewAgentWhichCommand())
+	cmd.AddCommand(NewAgentUninstallCommand())
+	cmd.AddCommand(NewAgentDiffCommand())
+	cmd.AddCommand(NewAgentRollbackCommand())
+
 	return cmd
 }
 
 // runAgentMenu displays the interactive agent management menu
 func runAgentMenu() {
+	if tui.Available() {
+		runAgentManager()
+		return
+	}
+
 	for {
 		choice := interactive.ShowAgentMenu()
 
@@ -37,6 +55,8 @@ func runAgentMenu() {
 			selectAgentsToInstall()
 		case "3":
 			viewCurrentAgents()
+		case "4":
+			selectAgentsToUninstall()
 		case "q", "Q":
 			fmt.Println("Goodbye!")
 			return
@@ -46,6 +66,76 @@ func runAgentMenu() {
 	}
 }
 
+// runAgentManager shows the full-screen agent manager until the user quits,
+// performing each install or uninstall they choose
+func runAgentManager() {
+	scope := config.UserScope
+	for {
+		items, err := agentItems()
+		if err != nil {
+			fmt.Printf("Error loading agents: %v\n", err)
+			return
+		}
+
+		selection, err := tui.RunAgentManager(items, scope)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		scope = selection.Scope
+
+		switch selection.Action {
+		case tui.ActionInstall:
+			var agents []resources.AgentResource
+			for _, name := range selection.Agents {
+				if agent, err := resources.GetAgent(name); err == nil {
+					agents = append(agents, agent)
+				}
+			}
+			fmt.Printf("Installing %d agents to %s scope...\n", len(agents), scope)
+			if results := installAgents(agents, scope, promptForConflicts()); results != nil {
+				printInstallSummary(results, len(agents))
+			}
+		case tui.ActionUninstall:
+			removed := uninstallAgents(selection.Agents, scope, interactive.ConfirmForceRemoval)
+			fmt.Printf("\n✓ Removed %d/%d selected agents\n", removed, len(selection.Agents))
+		default:
+			return
+		}
+
+		fmt.Print("\nPress Enter to return to the agent list...")
+		interactive.ReadInput()
+	}
+}
+
+// agentItems returns the built-in agents with their install status in each scope
+func agentItems() ([]tui.AgentItem, error) {
+	agents, err := resources.GetAvailableAgents()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make(map[string]map[config.Scope]string)
+	for _, scope := range []config.Scope{config.UserScope, config.ProjectScope} {
+		installed, err := config.GetInstalledAgents(scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s agents: %w", scope, err)
+		}
+		for _, state := range installed {
+			if status[state.Name] == nil {
+				status[state.Name] = make(map[config.Scope]string)
+			}
+			status[state.Name][scope] = state.Status
+		}
+	}
+
+	items := make([]tui.AgentItem, len(agents))
+	for i, agent := range agents {
+		items[i] = tui.AgentItem{Agent: agent, Status: status[agent.Name]}
+	}
+	return items, nil
+}
+
 // installAllAgents installs all available agents
 func installAllAgents() {
 	fmt.Println("\n=== Install All Agents ===")
@@ -77,18 +167,9 @@ func installAllAgents() {
 	// Install all agents
 	fmt.Printf("\nInstalling %d agents to %s scope...\n", len(agents), scope)
 
-	successCount := 0
-	for i, agent := range agents {
-		interactive.ShowProgress(i+1, len(agents), fmt.Sprintf("Installing %s", agent.Name))
-
-		if err := installer.InstallAgent(agent, scope); err != nil {
-			fmt.Printf("\nError installing %s: %v\n", agent.Name, err)
-			continue
-		}
-		successCount++
+	if results := installAgents(agents, scope, promptForConflicts()); results != nil {
+		printInstallSummary(results, len(agents))
 	}
-
-	fmt.Printf("\n✓ Successfully installed %d/%d agents\n", successCount, len(agents))
 }
 
 // selectAgentsToInstall shows interactive selection menu
@@ -137,54 +218,171 @@ func selectAgentsToInstall() {
 	// Install selected agents
 	fmt.Printf("\nInstalling %d selected agents to %s scope...\n", len(selectedAgents), scope)
 
-	successCount := 0
-	for i, agent := range selectedAgents {
-		interactive.ShowProgress(i+1, len(selectedAgents), fmt.Sprintf("Installing %s", agent.Name))
+	if results := installAgents(selectedAgents, scope, promptForConflicts()); results != nil {
+		printInstallSummary(results, len(selectedAgents))
+	}
+}
+
+// promptForConflicts returns install options that ask the user what to do
+// with each agent file that has local modifications
+func promptForConflicts() installer.InstallOptions {
+	return installer.InstallOptions{
+		OnConflict: func(name string) installer.ConflictPolicy {
+			fmt.Println()
+			return interactive.PromptConflictPolicy(name)
+		},
+	}
+}
 
-		if err := installer.InstallAgent(agent, scope); err != nil {
-			fmt.Printf("\nError installing %s: %v\n", agent.Name, err)
-			continue
+// installAgents installs agents to scope as one transaction, showing
+// progress. It returns the result for each agent, or nil if the install
+// was rolled back.
+func installAgents(agents []resources.AgentResource, scope config.Scope, opts installer.InstallOptions) []*installer.InstallResult {
+	opts.Progress = func(current, total int, name string) {
+		interactive.ShowProgress(current, total, fmt.Sprintf("Installing %s", name))
+	}
+
+	results, err := installer.InstallAgents(agents, scope, opts)
+	if err != nil {
+		fmt.Printf("\nError installing agents: %v\n", err)
+		return nil
+	}
+
+	return results
+}
+
+// printInstallSummary reports how many of total agents were written, and
+// lists the agents that were already up to date, kept with local
+// modifications or merged
+func printInstallSummary(results []*installer.InstallResult, total int) {
+	var written int
+	var unchanged, skipped, merged []string
+	var conflicted []*installer.InstallResult
+	for _, result := range results {
+		switch {
+		case result.Action == installer.ActionUnchanged:
+			unchanged = append(unchanged, result.Name)
+		case result.Action == installer.ActionSkipped:
+			skipped = append(skipped, result.Name)
+		case result.Conflicts > 0:
+			conflicted = append(conflicted, result)
+		case result.Action == installer.ActionMerged:
+			merged = append(merged, result.Name)
+			written++
+		default:
+			written++
 		}
-		successCount++
 	}
 
-	fmt.Printf("\n✓ Successfully installed %d/%d selected agents\n", successCount, len(selectedAgents))
+	fmt.Printf("\n✓ Installed %d/%d agents\n", written, total)
+	if len(merged) > 0 {
+		fmt.Printf("  ✓ %d merged with local modifications: %s\n", len(merged), strings.Join(merged, ", "))
+	}
+	if len(unchanged) > 0 {
+		fmt.Printf("  • %d already up to date: %s\n", len(unchanged), strings.Join(unchanged, ", "))
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("  • %d kept with local modifications: %s\n", len(skipped), strings.Join(skipped, ", "))
+	}
+	for _, result := range conflicted {
+		fmt.Printf("  ⚠ %s merged with %d conflicts; resolve the markers in %s\n", result.Name, result.Conflicts, result.Path)
+	}
 }
 
-// viewCurrentAgents displays currently installed agents
-func viewCurrentAgents() {
-	fmt.Println("\n=== Currently Installed Agents ===")
+// selectAgentsToUninstall shows installed agents in a scope and removes the selected ones
+func selectAgentsToUninstall() {
+	fmt.Println("\n=== Uninstall Agents ===")
 
-	userAgents, err := config.GetInstalledAgents(config.UserScope)
+	scope, err := interactive.PromptForScope()
 	if err != nil {
-		fmt.Printf("Error loading user agents: %v\n", err)
-	} else {
-		if len(userAgents) > 0 {
-			fmt.Println("\n🏠 User Scope Agents (~/.config/opencode/):")
-			for _, agent := range userAgents {
-				status := "✓"
-				fmt.Printf("  %s %s (installed %s)\n", status, agent.Name, agent.Installed.Format("2006-01-02"))
-			}
-		}
+		fmt.Printf("Error: %v\n", err)
+		return
 	}
 
-	projectAgents, err := config.GetInstalledAgents(config.ProjectScope)
+	installed, err := config.GetInstalledAgents(scope)
 	if err != nil {
-		fmt.Printf("Error loading project agents: %v\n", err)
-	} else {
-		if len(projectAgents) > 0 {
-			fmt.Println("\n📁 Project Scope Agents (.opencode/):")
-			for _, agent := range projectAgents {
-				status := "✓"
-				fmt.Printf("  %s %s (installed %s)\n", status, agent.Name, agent.Installed.Format("2006-01-02"))
-			}
-		}
+		fmt.Printf("Error loading %s agents: %v\n", scope, err)
+		return
 	}
 
-	if len(userAgents) == 0 && len(projectAgents) == 0 {
+	if len(installed) == 0 {
+		fmt.Printf("No agents installed in %s scope.\n", scope)
+		return
+	}
+
+	selectedNames := interactive.ShowUninstallSelection(installed)
+	if len(selectedNames) == 0 {
+		fmt.Println("No agents selected.")
+		return
+	}
+
+	if !interactive.ConfirmAgentRemoval(selectedNames, scope) {
+		fmt.Println("Uninstall cancelled.")
+		return
+	}
+
+	fmt.Println()
+	removed := uninstallAgents(selectedNames, scope, interactive.ConfirmForceRemoval)
+	fmt.Printf("\n✓ Removed %d/%d selected agents\n", removed, len(selectedNames))
+}
+
+// viewCurrentAgents displays currently installed agents
+func viewCurrentAgents() {
+	fmt.Println("\n=== Currently Installed Agents ===")
+
+	count, err := printInstalledAgents([]config.Scope{config.UserScope, config.ProjectScope})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else if count == 0 {
 		fmt.Println("No agents installed.")
 		fmt.Println("Use option 1 or 2 to install agents.")
 	}
 
 	fmt.Println() // Add spacing
 }
+
+// printInstalledAgents lists the agents installed in each scope and returns
+// how many were found
+func printInstalledAgents(scopes []config.Scope) (int, error) {
+	count := 0
+	for _, scope := range scopes {
+		agents, err := config.GetInstalledAgents(scope)
+		if err != nil {
+			return count, fmt.Errorf("failed to load %s agents: %w", scope, err)
+		}
+		if len(agents) == 0 {
+			continue
+		}
+
+		if scope == config.UserScope {
+			fmt.Println("\n🏠 User Scope Agents (~/.config/opencode/):")
+		} else {
+			fmt.Println("\n📁 Project Scope Agents (.opencode/):")
+		}
+		printAgentStates(agents)
+		count += len(agents)
+	}
+	return count, nil
+}
+
+// printAgentStates lists installed agents with their version and drift status
+func printAgentStates(agents []config.AgentState) {
+	for _, agent := range agents {
+		installed := agent.Installed.Format("2006-01-02")
+
+		switch agent.Status {
+		case config.StatusInstalled:
+			note := ""
+			if installer.UpdateAvailable(agent) {
+				note = ", update available"
+			}
+			fmt.Printf("  ✓ %s %s (installed %s%s)\n", agent.Name, agent.Version, installed, note)
+		case config.StatusModified:
+			fmt.Printf("  ⚠ %s %s (installed %s, modified since install)\n", agent.Name, agent.Version, installed)
+		case config.StatusMissing:
+			fmt.Printf("  ✗ %s %s (installed %s, file missing)\n", agent.Name, agent.Version, installed)
+		default:
+			fmt.Printf("  • %s (not installed by opencode-setup)\n", agent.Name)
+		}
+	}
+}
@@ -1,14 +1,17 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/interactive"
 	"github.com/gsmlg-dev/open-code-agents/pkg/orchestrator"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/gsmlg-dev/open-code-agents/pkg/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +28,7 @@ func NewCommandCommand() *cobra.Command {
 		NewWorkflowCommand(),
 		NewExecuteCommand(),
 		NewListCommand(),
+		NewSessionCommand(),
 	)
 
 	return cmd
@@ -32,59 +36,128 @@ func NewCommandCommand() *cobra.Command {
 
 // NewWorkflowCommand creates workflow management command
 func NewWorkflowCommand() *cobra.Command {
+	var output string
+
 	cmd := &cobra.Command{
 		Use:   "workflow",
-		Short: "Execute predefined workflows",
-		Long:  "Run predefined multi-agent workflows for common development tasks",
-		Run: func(cmd *cobra.Command, args []string) {
-			runWorkflowMenu()
+		Short: "Execute multi-agent workflows",
+		Long: `Run multi-agent workflows for common development tasks. Besides the
+predefined workflows, workflows can be defined as YAML files in the workflows
+directory of the user (~/.config/opencode/workflows) or project
+(.opencode/workflows) scope.
+Without a subcommand, a workflow is chosen from a menu; use "workflow run" in scripts.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+			return runWorkflowMenu(output)
 		},
 	}
+
+	addOutputFlag(cmd, &output)
+	cmd.AddCommand(NewWorkflowRunCommand())
+
 	return cmd
 }
 
 // NewExecuteCommand creates direct agent execution command
 func NewExecuteCommand() *cobra.Command {
+	var sessionName, output, input, inputFile string
+	var attach []string
+
 	cmd := &cobra.Command{
 		Use:   "execute [agent-name]",
 		Short: "Execute a specific agent",
-		Long:  "Execute a single agent with provided input",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			executeAgent(args[0])
+		Long: `Execute a single agent with provided input.
+
+The input is taken from --input or from --input-file (- for stdin). When
+neither flag is given, stdin is read if it is a pipe or a file; when it is a
+terminal you are prompted for the input, finishing with an empty line. Other
+kinds of stdin, such as a socket, are not read, so give the input by flag. Files given with --attach are sent to the agent with the input.
+Use --session to continue a named conversation with the agent across invocations.`,
+		Example: `  opencode-setup commands execute implementer --input "Add a health check endpoint"
+  git diff | opencode-setup commands execute reviewer
+  opencode-setup commands execute debugger --input-file issue.md --attach logs/error.log`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAgents,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			var attachments []agent.Attachment
+			for _, path := range attach {
+				attachment, err := agent.ReadAttachment(path)
+				if err != nil {
+					return err
+				}
+				attachments = append(attachments, attachment)
+			}
+
+			req := agent.ExecuteRequest{
+				AgentName:   args[0],
+				Session:     sessionName,
+				Attachments: attachments,
+			}
+			given := cmd.Flags().Changed("input") || cmd.Flags().Changed("input-file")
+			return executeAgent(req, input, inputFile, given, output)
 		},
 	}
+
+	cmd.Flags().StringVar(&sessionName, "session", "", "continue or start a named conversation session")
+	cmd.Flags().StringVarP(&input, "input", "i", "", "input for the agent")
+	cmd.Flags().StringVar(&inputFile, "input-file", "", "read the input from a file, or - for stdin")
+	cmd.Flags().StringArrayVar(&attach, "attach", nil, "attach a file as context (repeatable)")
+	cmd.MarkFlagsMutuallyExclusive("input", "input-file")
+	cmd.RegisterFlagCompletionFunc("session", completeSessions)
+	addOutputFlag(cmd, &output)
+
 	return cmd
 }
 
 // NewListCommand creates listing command
 func NewListCommand() *cobra.Command {
+	var output string
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List available agents and workflows",
-		Long:  "Show all available agents and predefined workflows",
-		Run: func(cmd *cobra.Command, args []string) {
-			listResources()
+		Long:  "Show all available agents and workflows, predefined and user-defined",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+			return listResources(output)
 		},
 	}
+
+	addOutputFlag(cmd, &output)
+
 	return cmd
 }
 
-// runWorkflowMenu displays workflow selection menu
-func runWorkflowMenu() {
-	fmt.Println("\n=== Available Workflows ===")
+// runWorkflowMenu displays workflow selection menu. The menu is written to
+// stderr when results are printed as JSON or YAML.
+func runWorkflowMenu(format string) error {
+	w := messageWriter(format)
+	fmt.Fprintln(w, "\n=== Available Workflows ===")
 
-	workflows := orchestrator.ListWorkflows()
+	workflows, skipped := orchestrator.ListWorkflowsWithErrors()
+	warnSkipped(skipped)
 	for i, workflow := range workflows {
-		fmt.Printf("%d. %s - %s\n", i+1, workflow.Name, workflow.Description)
+		fmt.Fprintf(w, "%d. %s - %s\n", i+1, workflow.Name, workflow.Description)
 	}
-	fmt.Println("B. Back to main menu")
+	fmt.Fprintln(w, "B. Back to main menu")
 
-	fmt.Print("\nSelect workflow: ")
-	choice := readInput()
+	fmt.Fprint(w, "\nSelect workflow: ")
+	choice := interactive.ReadInput()
 
 	if strings.ToUpper(choice) == "B" {
-		return
+		return nil
 	}
 
 	// Parse workflow selection
@@ -97,19 +170,18 @@ func runWorkflowMenu() {
 	}
 
 	if selectedWorkflow.Name == "" {
-		fmt.Println("Invalid selection.")
-		return
+		return fmt.Errorf("invalid workflow selection %q", choice)
 	}
 
 	// Get workflow parameters
-	fmt.Printf("\n=== %s Workflow ===\n", strings.Title(selectedWorkflow.Name))
-	fmt.Println("This workflow will execute the following steps:")
+	fmt.Fprintf(w, "\n=== %s Workflow ===\n", strings.Title(selectedWorkflow.Name))
+	fmt.Fprintln(w, "This workflow will execute the following steps:")
 	for i, step := range selectedWorkflow.Steps {
-		fmt.Printf("%d. %s agent\n", i+1, step.AgentName)
+		fmt.Fprintf(w, "%d. %s agent\n", i+1, step.AgentName)
 	}
 
-	fmt.Print("\nEnter workflow context (key=value, comma-separated): ")
-	contextInput := readInput()
+	fmt.Fprint(w, "\nEnter workflow context (key=value, comma-separated): ")
+	contextInput := interactive.ReadInput()
 
 	workflowContext := make(map[string]string)
 	if contextInput != "" {
@@ -123,32 +195,52 @@ func runWorkflowMenu() {
 	}
 
 	// Execute workflow
-	fmt.Printf("\nExecuting %s workflow...\n", selectedWorkflow.Name)
-	executeWorkflow(selectedWorkflow, workflowContext)
+	fmt.Fprintf(w, "\nExecuting %s workflow...\n", selectedWorkflow.Name)
+	return executeWorkflow(selectedWorkflow, workflowContext, format)
 }
 
-// executeAgent executes a single agent
-func executeAgent(agentName string) {
-	fmt.Printf("\n=== Execute %s Agent ===\n", strings.Title(agentName))
-	fmt.Print("Enter input for the agent: ")
-	input := readInput()
+// executeAgent executes a single agent, printing the response in format.
+// The request input is read from input, inputFile, piped stdin or a prompt;
+// given reports whether input or inputFile was set by flag.
+func executeAgent(req agent.ExecuteRequest, input, inputFile string, given bool, format string) error {
+	agentName := req.AgentName
 
-	if input == "" {
-		fmt.Println("No input provided.")
-		return
+	var err error
+	req.Input, err = readAgentInput(agentName, input, inputFile, given, messageWriter(format))
+	if err != nil {
+		return err
+	}
+	if req.Input == "" {
+		return fmt.Errorf("no input provided")
 	}
 
-	engine := agent.NewEngine()
-	req := agent.ExecuteRequest{
-		AgentName: agentName,
-		Input:     input,
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return err
 	}
 
 	ctx := context.Background()
 	response, err := engine.Execute(ctx, req)
 	if err != nil {
-		fmt.Printf("Error executing agent: %v\n", err)
-		return
+		return fmt.Errorf("failed to execute agent: %w", err)
+	}
+
+	if format != outputTable {
+		if err := printStructured(format, response); err != nil {
+			return err
+		}
+		if !response.Success {
+			return fmt.Errorf("agent %s failed: %s", agentName, response.Error)
+		}
+		return nil
+	}
+
+	if !response.Success {
+		return fmt.Errorf("%s", response.Error)
+	}
+
+	if response.Compacted {
+		fmt.Println("\nNote: older turns of this session were summarised to fit the context window.")
 	}
 
 	fmt.Printf("\n--- Agent Output ---\n%s\n", response.Output)
@@ -158,22 +250,55 @@ func executeAgent(agentName string) {
 		fmt.Printf("Next Agent: %s\n", response.Handoff.AgentName)
 		fmt.Printf("Reason: %s\n", response.Handoff.Reason)
 	}
+	return nil
 }
 
-// executeWorkflow executes a workflow with given context
-func executeWorkflow(workflow orchestrator.Workflow, workflowContext map[string]string) {
-	orch := orchestrator.NewOrchestrator()
+// executeWorkflow executes a workflow with given context, printing the
+// result in format. In a terminal, progress is shown in a live view while the
+// workflow runs. It returns an error if the workflow fails.
+func executeWorkflow(workflow orchestrator.Workflow, workflowContext map[string]string, format string) error {
+	orch, err := orchestrator.LoadOrchestrator()
+	if err != nil {
+		return err
+	}
 
-	// Set workflow context
-	workflow.Context = workflowContext
+	// Given values override the workflow's default context
+	merged := make(map[string]string)
+	for k, v := range workflow.Context {
+		merged[k] = v
+	}
+	for k, v := range workflowContext {
+		merged[k] = v
+	}
+	workflow.Context = merged
 
 	ctx := context.Background()
-	result, err := orch.ExecuteWorkflow(ctx, workflow)
+	var result *orchestrator.WorkflowResult
+	if format == outputTable && tui.Available() {
+		result, err = tui.RunWorkflow(ctx, orch, workflow)
+	} else {
+		result, err = orch.ExecuteWorkflow(ctx, workflow)
+	}
 	if err != nil {
-		fmt.Printf("Error executing workflow: %v\n", err)
-		return
+		return fmt.Errorf("failed to execute workflow: %w", err)
 	}
 
+	if format != outputTable {
+		if err := printStructured(format, result); err != nil {
+			return err
+		}
+	} else {
+		printWorkflowResult(result)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("workflow %s failed", result.WorkflowName)
+	}
+	return nil
+}
+
+// printWorkflowResult prints the outcome of each workflow step
+func printWorkflowResult(result *orchestrator.WorkflowResult) {
 	fmt.Printf("\n--- Workflow Results ---\n")
 	fmt.Printf("Workflow: %s\n", result.WorkflowName)
 	fmt.Printf("Success: %t\n", result.Success)
@@ -201,35 +326,96 @@ func executeWorkflow(workflow orchestrator.Workflow, workflowContext map[string]
 	}
 }
 
-// listResources shows all available agents and workflows
-func listResources() {
-	fmt.Println("\n=== Available Agents ===")
+// resourceList is the structured output of the list command
+type resourceList struct {
+	Agents    []resources.AgentResource `json:"agents"`
+	Workflows []orchestrator.Workflow   `json:"workflows"`
+}
 
-	engine := agent.NewEngine()
-	agents, err := engine.ListAvailableAgents()
+// listResources shows all available agents and workflows
+func listResources(format string) error {
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return err
+	}
+	agents, skipped, err := engine.ListAvailableAgentsWithErrors()
 	if err != nil {
-		fmt.Printf("Error loading agents: %v\n", err)
-		return
+		return fmt.Errorf("failed to load agents: %w", err)
 	}
+	workflows, skippedWorkflows := orchestrator.ListWorkflowsWithErrors()
+	warnSkipped(append(skipped, skippedWorkflows...))
 
+	if format != outputTable {
+		return printStructured(format, resourceList{Agents: agents, Workflows: workflows})
+	}
+
+	fmt.Println("\n=== Available Agents ===")
 	for _, agent := range agents {
 		fmt.Printf("• %s - %s\n", agent.Name, agent.Description)
 	}
 
 	fmt.Println("\n=== Available Workflows ===")
-	workflows := orchestrator.ListWorkflows()
 	for _, workflow := range workflows {
 		fmt.Printf("• %s - %s\n", workflow.Name, workflow.Description)
 	}
+	return nil
 }
 
-// Helper functions
-func readInput() string {
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	return strings.TrimSpace(input)
+// readAgentInput returns the input given by flag or read from a file. When
+// neither was given by flag, it reads stdin if it is a pipe or file, or
+// prompts for the input on w if stdin is a terminal.
+func readAgentInput(agentName, input, inputFile string, given bool, w io.Writer) (string, error) {
+	mode := stdinMode()
+	switch {
+	case input != "":
+		return input, nil
+	case inputFile == "-" || (!given && (mode&os.ModeNamedPipe != 0 || mode.IsRegular())):
+		data, err := io.ReadAll(interactive.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input from stdin: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case inputFile != "":
+		data, err := os.ReadFile(inputFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case given || mode&os.ModeCharDevice == 0:
+		// An empty flag, or a stdin such as a socket that may never be closed
+		return "", nil
+	}
+
+	fmt.Fprintf(w, "\n=== Execute %s Agent ===\n", strings.Title(agentName))
+	fmt.Fprintln(w, "Enter input for the agent (finish with an empty line):")
+
+	var lines []string
+	for {
+		line, err := interactive.Stdin.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" && (err != nil || len(lines) > 0) {
+			break
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// stdinMode returns the file mode of stdin, or 0 if it cannot be read
+func stdinMode() os.FileMode {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Mode()
 }
 
+// Helper functions
 func parseIndex(s string) int {
 	var num int
 	fmt.Sscanf(s, "%d", &num)
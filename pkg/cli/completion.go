@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/orchestrator"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/spf13/cobra"
+)
+
+// Completion functions for cobra's generated shell completion scripts.
+// Candidates are "value\tdescription" so shells that show descriptions can
+// display them. Errors while loading candidates give no suggestions rather
+// than failing, since completion output cannot report them.
+
+// completeAgents completes the names of all agents that can be executed:
+// built-in agents and custom agents installed in either scope
+func completeAgents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	agents, _, err := engine.ListAvailableAgentsWithErrors()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return agentCandidates(agents, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBuiltinAgents completes the names of the built-in agents that have
+// not been given yet, for commands that accept several agents
+func completeBuiltinAgents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	agents, err := resources.GetAvailableAgents()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return agentCandidates(agents, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBuiltinAgent completes a single built-in agent name
+func completeBuiltinAgent(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeBuiltinAgents(cmd, args, toComplete)
+}
+
+// completeInstalledAgents completes the agents installed in the scope given
+// by --scope, or in either scope if it is not set yet
+func completeInstalledAgents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		given[arg] = true
+	}
+
+	var names []string
+	for _, scope := range flagScopes(cmd) {
+		installed, err := config.GetInstalledAgents(scope)
+		if err != nil {
+			continue
+		}
+		for _, state := range installed {
+			if state.Status == config.StatusMissing || given[state.Name] {
+				continue
+			}
+			given[state.Name] = true
+			names = append(names, state.Name+"\t"+string(scope)+" scope")
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkflows completes workflow names
+func completeWorkflows(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	workflows, _ := orchestrator.ListWorkflowsWithErrors()
+
+	var names []string
+	for _, workflow := range workflows {
+		names = append(names, workflow.Name+"\t"+workflow.Description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeContextKeys completes the "key=" part of --set and --set-file with
+// the placeholders of the workflow given as the first argument that have no
+// value yet
+func completeContextKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 || strings.Contains(toComplete, "=") {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	workflow, err := orchestrator.GetWorkflow(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	given := make(map[string]bool)
+	for _, flag := range []string{"set", "set-file"} {
+		values, _ := cmd.Flags().GetStringArray(flag)
+		for _, value := range values {
+			key, _, _ := strings.Cut(value, "=")
+			given[key] = true
+		}
+	}
+
+	var keys []string
+	for _, key := range workflow.Placeholders() {
+		if !given[key] {
+			keys = append(keys, key+"=")
+		}
+	}
+	return keys, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSessions completes the names of saved sessions not given yet
+func completeSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	engine, err := agent.LoadEngine()
+	if err != nil || engine.Sessions() == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	sessions, err := engine.Sessions().List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	given := make(map[string]bool)
+	for _, arg := range args {
+		given[arg] = true
+	}
+
+	var names []string
+	for _, sess := range sessions {
+		if !given[sess.Name] {
+			names = append(names, sess.Name+"\t"+sess.AgentName+" agent")
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSession completes a single session name
+func completeSession(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSessions(cmd, args, toComplete)
+}
+
+// completeBackups completes the IDs of the install runs that can be rolled
+// back in the scope given by --scope, newest first
+func completeBackups(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var ids []string
+	for _, scope := range flagScopes(cmd) {
+		backups, err := installer.ListBackups(scope)
+		if err != nil {
+			continue
+		}
+		for _, backup := range backups {
+			ids = append(ids, backup.ID+"\t"+string(scope)+": "+strings.Join(backup.Agents, ", "))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeConfigKeys completes config keys: the known keys, the entries set
+// in map keys such as prompt_vars, and "prompt_vars." to start a new entry.
+// For config set, the values of boolean keys are completed too.
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		if len(args) == 1 && cmd.Name() == "set" {
+			if key, err := config.LookupKey(args[0]); err == nil && key.Kind == config.KindBool {
+				return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var keys []string
+	known := make(map[string]bool)
+	for _, key := range config.Keys() {
+		known[key.Name] = true
+		if key.Kind == config.KindMap {
+			keys = append(keys, key.Name+".")
+		} else {
+			keys = append(keys, key.Name+"\t"+key.Kind)
+		}
+	}
+	// Values of other keys are entries of map keys
+	if effective, err := config.LoadEffective(); err == nil {
+		for _, v := range effective.Values {
+			if !known[v.Key] {
+				keys = append(keys, v.Key+"\t"+v.Origin)
+			}
+		}
+	}
+
+	// Keep the cursor after "prompt_vars." when that is the only match
+	var matches []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, toComplete) {
+			matches = append(matches, key)
+		}
+	}
+	directive := cobra.ShellCompDirectiveNoFileComp
+	if len(matches) == 1 && strings.HasSuffix(matches[0], ".") {
+		directive |= cobra.ShellCompDirectiveNoSpace
+	}
+	return matches, directive
+}
+
+// completeScopes completes --scope values
+func completeScopes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{
+		string(config.UserScope) + "\t~/.config/opencode",
+		string(config.ProjectScope) + "\t.opencode in the current directory",
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOutputFormats completes --output values
+func completeOutputFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{outputTable, outputJSON, outputYAML}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConflictPolicies completes --on-conflict values
+func completeConflictPolicies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{
+		string(installer.ConflictSkip) + "\tkeep the local file",
+		string(installer.ConflictOverwrite) + "\treplace it with the new version",
+		string(installer.ConflictMerge) + "\tmerge local changes into the new version",
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// agentCandidates returns the agents not among given, with their descriptions
+func agentCandidates(agents []resources.AgentResource, given []string) []string {
+	skip := make(map[string]bool)
+	for _, name := range given {
+		skip[name] = true
+	}
+
+	var names []string
+	for _, a := range agents {
+		if !skip[a.Name] {
+			names = append(names, a.Name+"\t"+a.Description)
+		}
+	}
+	return names
+}
+
+// flagScopes returns the scope selected with --scope, or both scopes if the
+// flag is not set or not valid
+func flagScopes(cmd *cobra.Command) []config.Scope {
+	if flag := cmd.Flags().Lookup("scope"); flag != nil {
+		if scope, err := config.ParseScope(flag.Value.String()); err == nil {
+			return []config.Scope{scope}
+		}
+	}
+	return []config.Scope{config.UserScope, config.ProjectScope}
+}
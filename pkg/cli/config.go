@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCommand creates the command that reads and writes configuration
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Get, set and show configuration",
+		Long: `Read and change configuration. The effective configuration is merged from,
+lowest precedence first:
+
+  default   built-in defaults
+  user      ~/.config/opencode/config.json
+  project   .opencode/config.json
+  env       OPENCODE_* environment variables
+
+Keys are dotted paths such as settings.log_level or prompt_vars.team. The
+environment variable for a key is OPENCODE_ followed by the key in upper case
+with dots replaced by underscores, e.g. OPENCODE_SETTINGS_LOG_LEVEL.`,
+	}
+
+	var showOutput, migrateOutput string
+	var origin bool
+	var setScope, unsetScope, editScope, migrateScope string
+
+	getCmd := &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print the effective value of a key",
+		Long:              "Print the effective value of a key, or every value under it for keys such as settings or prompt_vars.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return getConfig(args[0])
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a key in the user or project config file",
+		Long: `Set a key in the config file of a scope, keeping the rest of the file.
+Lists such as settings.agent_resolution are given comma-separated.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			scope, err := config.ParseScope(setScope)
+			if err != nil {
+				return err
+			}
+			if err := config.Set(scope, args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Set %s in %s config\n", args[0], scope)
+			return nil
+		},
+	}
+	setCmd.Flags().StringVar(&setScope, "scope", "", "config file to change (user or project)")
+	setCmd.MarkFlagRequired("scope")
+	setCmd.RegisterFlagCompletionFunc("scope", completeScopes)
+
+	unsetCmd := &cobra.Command{
+		Use:               "unset <key>",
+		Short:             "Remove a key from the user or project config file",
+		Long:              "Remove a key from the config file of a scope, so that its value comes from the layers below.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			scope, err := config.ParseScope(unsetScope)
+			if err != nil {
+				return err
+			}
+			removed, err := config.Unset(scope, args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				fmt.Printf("• %s is not set in %s config\n", args[0], scope)
+				return nil
+			}
+			fmt.Printf("✓ Removed %s from %s config\n", args[0], scope)
+			return nil
+		},
+	}
+	unsetCmd.Flags().StringVar(&unsetScope, "scope", "", "config file to change (user or project)")
+	unsetCmd.MarkFlagRequired("scope")
+	unsetCmd.RegisterFlagCompletionFunc("scope", completeScopes)
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration",
+		Long: `Show the configuration merged from the defaults, config files and environment.
+With --origin, show which layer set each value.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(showOutput); err != nil {
+				return err
+			}
+			return showConfig(origin, showOutput)
+		},
+	}
+	showCmd.Flags().BoolVar(&origin, "origin", false, "show the layer each value comes from")
+	addOutputFlag(showCmd, &showOutput)
+
+	editCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open a config file in your editor",
+		Long: `Open the config file of a scope in $VISUAL or $EDITOR (default vi) and
+check it when the editor exits.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			scope, err := config.ParseScope(editScope)
+			if err != nil {
+				return err
+			}
+			return editConfig(scope)
+		},
+	}
+	editCmd.Flags().StringVar(&editScope, "scope", "", "config file to edit (user or project)")
+	editCmd.MarkFlagRequired("scope")
+	editCmd.RegisterFlagCompletionFunc("scope", completeScopes)
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade config files to the current schema version",
+		Long: `Upgrade the user and project config files written by older versions of
+opencode-setup to the current schema version. The old file is kept next to
+it as config.json.v<version>.bak. Older files are also migrated in memory
+whenever they are read, and upgraded on disk when they are next changed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(migrateOutput); err != nil {
+				return err
+			}
+			scopes := []config.Scope{config.UserScope, config.ProjectScope}
+			if migrateScope != "" {
+				scope, err := config.ParseScope(migrateScope)
+				if err != nil {
+					return err
+				}
+				scopes = []config.Scope{scope}
+			}
+			return migrateConfig(scopes, migrateOutput)
+		},
+	}
+	migrateCmd.Flags().StringVar(&migrateScope, "scope", "", "only migrate this scope (user or project)")
+	migrateCmd.RegisterFlagCompletionFunc("scope", completeScopes)
+	addOutputFlag(migrateCmd, &migrateOutput)
+
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of config files",
+		Long: `Print a JSON Schema for config.json, so editors can validate and complete it:
+
+  opencode-setup config schema > .opencode/config.schema.json
+
+then add "$schema": "./config.schema.json" to .opencode/config.json.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return printStructured(outputJSON, config.JSONSchema())
+		},
+	}
+
+	cmd.AddCommand(getCmd, setCmd, unsetCmd, showCmd, editCmd, migrateCmd, schemaCmd)
+
+	return cmd
+}
+
+// getConfig prints the effective value of a key
+func getConfig(key string) error {
+	effective, err := config.LoadEffective()
+	if err != nil {
+		return err
+	}
+
+	values, err := effective.Get(key)
+	if err != nil {
+		return err
+	}
+	if len(values) == 1 && values[0].Key == key {
+		fmt.Println(config.FormatValue(values[0].Value))
+		return nil
+	}
+	for _, v := range values {
+		fmt.Printf("%s = %s\n", v.Key, config.FormatValue(v.Value))
+	}
+	return nil
+}
+
+// showConfig prints the effective configuration, optionally with the layer
+// each value comes from
+func showConfig(origin bool, format string) error {
+	effective, err := config.LoadEffective()
+	if err != nil {
+		return err
+	}
+
+	if format != outputTable {
+		if origin {
+			return printStructured(format, effective.Values)
+		}
+		return printStructured(format, effective.Config)
+	}
+
+	fmt.Println("=== Effective Configuration ===")
+	width := 0
+	for _, v := range effective.Values {
+		if len(v.Key) > width {
+			width = len(v.Key)
+		}
+	}
+	for _, v := range effective.Values {
+		value := config.FormatValue(v.Value)
+		if !origin {
+			fmt.Printf("%-*s = %s\n", width, v.Key, value)
+			continue
+		}
+		source := v.Origin
+		if v.Source != "" {
+			source += " (" + relativePath(v.Source) + ")"
+		}
+		fmt.Printf("%-*s = %s  [%s]\n", width, v.Key, value, source)
+	}
+	return nil
+}
+
+// editConfig opens a scope's config file in the user's editor and checks
+// the result. A file created for editing is removed if it is left unchanged.
+func editConfig(scope config.Scope) error {
+	path, err := config.GetConfigFilePath(scope)
+	if err != nil {
+		return err
+	}
+
+	initial := fmt.Sprintf("{\n  \"version\": %d\n}\n", config.CurrentVersion)
+	created := false
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		dir, err := config.GetConfigPath(scope)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(initial), 0644); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		created = true
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may include arguments, such as "code --wait"
+	fields := strings.Fields(editor)
+	editCmd := exec.Command(fields[0], append(fields[1:], path)...)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %s: %w", editor, err)
+	}
+
+	if created {
+		if data, err := os.ReadFile(path); err == nil && bytes.Equal(bytes.TrimSpace(data), bytes.TrimSpace([]byte(initial))) {
+			os.Remove(path)
+			fmt.Printf("• %s left unchanged, removed\n", path)
+			return nil
+		}
+	}
+
+	unknown, err := config.CheckConfigFile(scope)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return fmt.Errorf("config file is invalid; fix it with 'opencode-setup config edit --scope %s'", scope)
+	}
+	for _, key := range unknown {
+		fmt.Printf("⚠ %s: unknown key %s is ignored\n", path, key)
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+	return nil
+}
+
+// migrateConfig upgrades the config files of scopes and reports the result
+func migrateConfig(scopes []config.Scope, format string) error {
+	var results []*config.MigrationResult
+	for _, scope := range scopes {
+		result, err := config.Migrate(scope)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			if format == outputTable {
+				fmt.Printf("• %s: no config file\n", scope)
+			}
+			continue
+		}
+		results = append(results, result)
+		if format != outputTable {
+			continue
+		}
+		if len(result.Applied) == 0 {
+			fmt.Printf("• %s: %s is at version %d\n", scope, relativePath(result.Path), result.To)
+			continue
+		}
+		fmt.Printf("✓ %s: migrated %s from version %d to %d (backup: %s)\n",
+			scope, relativePath(result.Path), result.From, result.To, relativePath(result.Backup))
+		for _, desc := range result.Applied {
+			fmt.Printf("    - %s\n", desc)
+		}
+	}
+
+	if format != outputTable {
+		if results == nil {
+			results = []*config.MigrationResult{}
+		}
+		return printStructured(format, results)
+	}
+	return nil
+}
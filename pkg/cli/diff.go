@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentDiffCommand creates the command that previews agent upgrades
+func NewAgentDiffCommand() *cobra.Command {
+	var scopeName string
+
+	cmd := &cobra.Command{
+		Use:   "diff [name]",
+		Short: "Show what reinstalling agents would change",
+		Long: `Show a unified diff between each installed agent file and the file that
+installing the current embedded agent would write, for each scope.
+Without a name, every installed built-in agent is compared.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeBuiltinAgent,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			scopes := []config.Scope{config.UserScope, config.ProjectScope}
+			if scopeName != "" {
+				scope, err := config.ParseScope(scopeName)
+				if err != nil {
+					return err
+				}
+				scopes = []config.Scope{scope}
+			}
+
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+				if _, err := resources.GetAgent(name); err != nil {
+					return fmt.Errorf("%s: %w", name, installer.ErrNotManaged)
+				}
+			}
+
+			return showAgentDiffs(name, scopes)
+		},
+	}
+
+	cmd.Flags().StringVar(&scopeName, "scope", "", "only compare agents in this scope (user or project)")
+	cmd.RegisterFlagCompletionFunc("scope", completeScopes)
+
+	return cmd
+}
+
+// showAgentDiffs prints the upgrade diff of one or all installed built-in agents
+func showAgentDiffs(name string, scopes []config.Scope) error {
+	for _, scope := range scopes {
+		names := []string{name}
+		if name == "" {
+			installed, err := config.GetInstalledAgents(scope)
+			if err != nil {
+				return fmt.Errorf("failed to get %s agents: %w", scope, err)
+			}
+			names = names[:0]
+			for _, agent := range installed {
+				if _, err := resources.GetAgent(agent.Name); err == nil && agent.Status != config.StatusMissing {
+					names = append(names, agent.Name)
+				}
+			}
+		}
+
+		fmt.Printf("=== %s scope ===\n", scope)
+		if len(names) == 0 {
+			fmt.Println("No built-in agents installed.")
+		}
+
+		for _, agentName := range names {
+			d, err := installer.DiffAgent(agentName, scope)
+			switch {
+			case errors.Is(err, installer.ErrNotInstalled):
+				fmt.Printf("  %s is not installed\n", agentName)
+			case err != nil:
+				return err
+			case d == "":
+				fmt.Printf("✓ %s is up to date\n", agentName)
+			default:
+				fmt.Print(d)
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/doctor"
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCommand creates the command that diagnoses setup problems
+func NewDoctorCommand() *cobra.Command {
+	var jsonOutput, offline bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with the OpenCode setup",
+		Long: `Check the OpenCode setup and suggest fixes:
+
+  - config directories exist and are writable in both scopes
+  - config.json files and OPENCODE_* environment variables are valid
+  - installed agents parse and lint cleanly
+  - agents shadowed by an agent of the same name in another scope
+  - installed built-in agents match the versions embedded in this binary
+  - API keys are set for the providers of the models in use, resolved from the
+    effective configuration and agents as when they are executed
+  - provider endpoints are reachable (skipped with --offline)
+
+Exits with a nonzero status when any check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			report := doctor.Run(context.Background(), doctor.Options{Offline: offline, Timeout: timeout})
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal report: %w", err)
+				}
+				fmt.Println(string(data))
+			} else {
+				printReport(report)
+			}
+
+			if report.HasErrors() {
+				return errors.New("doctor found problems")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the report as JSON")
+	cmd.Flags().BoolVar(&offline, "offline", false, "skip the provider endpoint checks")
+	cmd.Flags().DurationVar(&timeout, "timeout", doctor.DefaultTimeout, "timeout for each endpoint check")
+
+	return cmd
+}
+
+// checkTitles are the section headings of the doctor report
+var checkTitles = map[string]string{
+	doctor.CheckDirectories: "Config Directories",
+	doctor.CheckConfig:      "Config Files",
+	doctor.CheckAgents:      "Installed Agents",
+	doctor.CheckShadowing:   "Shadowed Agents",
+	doctor.CheckEmbedded:    "Built-in Agent Versions",
+	doctor.CheckCredentials: "Provider Credentials",
+	doctor.CheckEndpoints:   "Provider Endpoints",
+}
+
+// printReport prints the doctor results grouped by check, with fixes
+func printReport(report *doctor.Report) {
+	check := ""
+	for _, result := range report.Results {
+		if result.Check != check {
+			check = result.Check
+			fmt.Printf("\n=== %s ===\n", checkTitles[check])
+		}
+
+		mark := "✓"
+		switch result.Status {
+		case doctor.StatusError:
+			mark = "✗"
+		case doctor.StatusWarning:
+			mark = "⚠"
+		case doctor.StatusSkipped:
+			mark = "•"
+		}
+		fmt.Printf("%s %s\n", mark, result.Message)
+		if result.Fix != "" {
+			fmt.Printf("  → %s\n", result.Fix)
+		}
+	}
+
+	if report.Errors == 0 && report.Warnings == 0 {
+		fmt.Println("\n✓ No problems found")
+		return
+	}
+	fmt.Printf("\n%d errors, %d warnings\n", report.Errors, report.Warnings)
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// NewInitCommand creates the command that sets up a project's .opencode directory
+func NewInitCommand() *cobra.Command {
+	var agents []string
+	var force bool
+	var onConflict, output string
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up .opencode for the project in the current directory",
+		Long: `Detect the project's language and framework (from go.mod, package.json,
+pyproject.toml or Cargo.toml) and create .opencode/ with:
+
+  - config.json with prompt variables for the project's build, test and lint commands
+  - the implementer, reviewer, tester and debugger agents (or those given with --agents)
+  - sample workflows in .opencode/workflows
+  - a .gitignore for install backups, staging directories and sessions
+
+Files that already exist are kept unless --force is given, so init can be
+re-run to add missing pieces.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			opts := scaffold.Options{Force: force}
+			if cmd.Flags().Changed("agents") {
+				opts.Agents = agents
+			}
+			if onConflict != "" {
+				policy, err := installer.ParseConflictPolicy(onConflict)
+				if err != nil {
+					return err
+				}
+				opts.OnConflict = func(string) installer.ConflictPolicy { return policy }
+			}
+
+			result, err := scaffold.Init(opts)
+			if err != nil {
+				return err
+			}
+
+			if output != outputTable {
+				return printStructured(output, result)
+			}
+			printInitResult(result)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&agents, "agents", scaffold.DefaultAgents, "agents to install (comma-separated)")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite config, workflow and .gitignore files that already exist")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "how to handle locally modified agents (skip, overwrite or merge; default skip)")
+	addOutputFlag(cmd, &output)
+	cmd.RegisterFlagCompletionFunc("agents", completeBuiltinAgents)
+	cmd.RegisterFlagCompletionFunc("on-conflict", completeConflictPolicies)
+
+	return cmd
+}
+
+// printInitResult reports the detected project and what init wrote
+func printInitResult(result *scaffold.Result) {
+	info := result.Project
+	fmt.Printf("=== Initialized %s ===\n", result.Dir)
+
+	switch {
+	case info.Language == "":
+		fmt.Printf("Project: %s (language not detected)\n", info.Name)
+	case info.Framework != "":
+		fmt.Printf("Project: %s (%s, %s; from %s)\n", info.Name, info.Language, info.Framework, info.Manifest)
+	default:
+		fmt.Printf("Project: %s (%s; from %s)\n", info.Name, info.Language, info.Manifest)
+	}
+
+	fmt.Println("\nFiles:")
+	for _, file := range result.Files {
+		path := relativePath(file.Path)
+		switch file.Action {
+		case scaffold.FileSkipped:
+			fmt.Printf("  • %s already exists, kept\n", path)
+		default:
+			fmt.Printf("  ✓ %s %s\n", path, file.Action)
+		}
+	}
+
+	fmt.Println("\nAgents:")
+	for _, agent := range result.Agents {
+		switch {
+		case agent.Action == installer.ActionSkipped:
+			fmt.Printf("  • %s kept with local modifications\n", agent.Name)
+		case agent.Conflicts > 0:
+			fmt.Printf("  ⚠ %s merged with %d conflicts; resolve the markers in %s\n", agent.Name, agent.Conflicts, agent.Path)
+		default:
+			fmt.Printf("  ✓ %s %s\n", agent.Name, agent.Action)
+		}
+	}
+
+	fmt.Println("\nNext steps:")
+	fmt.Println("  opencode-setup commands list")
+	fmt.Println(`  opencode-setup commands workflow run quick-fix --set bug_description="..."`)
+	fmt.Println("  opencode-setup doctor")
+}
+
+// relativePath shortens path relative to the current directory when possible
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/interactive"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentInstallCommand creates the non-interactive agent install command
+func NewAgentInstallCommand() *cobra.Command {
+	var scopeName, onConflict string
+	var all, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "install [name...]",
+		Short: "Install agents without the interactive menu",
+		Long: `Install the named agents, or every built-in agent with --all, to the user or
+project scope. The install is all or nothing and can be undone with
+"agents rollback".
+
+Agent files modified since they were installed are handled according to
+--on-conflict. Without it you are asked about each file, or with --yes the
+local modifications are kept.
+
+The command fails if a named agent does not exist, the install fails, or a
+merge leaves conflicts to resolve.`,
+		ValidArgsFunction: completeBuiltinAgents,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			if all == (len(args) > 0) {
+				return errors.New("specify agent names or --all")
+			}
+
+			scope, err := config.ParseScope(scopeName)
+			if err != nil {
+				return err
+			}
+
+			opts := installer.InstallOptions{}
+			switch {
+			case onConflict != "":
+				policy, err := installer.ParseConflictPolicy(onConflict)
+				if err != nil {
+					return err
+				}
+				opts.OnConflict = func(string) installer.ConflictPolicy { return policy }
+			case yes:
+				opts.OnConflict = func(string) installer.ConflictPolicy { return installer.ConflictSkip }
+			default:
+				opts = promptForConflicts()
+			}
+
+			return installNamedAgents(args, all, scope, yes, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&scopeName, "scope", "", "scope to install agents to (user or project)")
+	cmd.Flags().BoolVar(&all, "all", false, "install all built-in agents")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "how to handle locally modified agents (skip, overwrite or merge)")
+	cmd.MarkFlagRequired("scope")
+	cmd.RegisterFlagCompletionFunc("scope", completeScopes)
+	cmd.RegisterFlagCompletionFunc("on-conflict", completeConflictPolicies)
+
+	return cmd
+}
+
+// installNamedAgents installs the named built-in agents, or all of them, and
+// returns an error if any agent could not be installed cleanly
+func installNamedAgents(names []string, all bool, scope config.Scope, yes bool, opts installer.InstallOptions) error {
+	var agents []resources.AgentResource
+	var missing []string
+
+	if all {
+		available, err := resources.GetAvailableAgents()
+		if err != nil {
+			return fmt.Errorf("failed to load agents: %w", err)
+		}
+		agents = available
+	} else {
+		for _, name := range names {
+			agent, err := resources.GetAgent(name)
+			if err != nil {
+				fmt.Printf("✗ Agent %s not found\n", name)
+				missing = append(missing, name)
+				continue
+			}
+			agents = append(agents, agent)
+		}
+	}
+
+	if len(agents) == 0 {
+		return errors.New("no agents to install")
+	}
+
+	if !yes && !interactive.ConfirmAgentInstallation(agents, scope) {
+		return errors.New("installation cancelled")
+	}
+
+	fmt.Printf("\nInstalling %d agents to %s scope...\n", len(agents), scope)
+
+	results := installAgents(agents, scope, opts)
+	if results == nil {
+		return errors.New("no agents were installed")
+	}
+
+	printInstallSummary(results, len(agents)+len(missing))
+
+	var conflicted []string
+	for _, result := range results {
+		if result.Conflicts > 0 {
+			conflicted = append(conflicted, result.Name)
+		}
+	}
+
+	switch {
+	case len(missing) > 0:
+		return fmt.Errorf("agents not found: %s", strings.Join(missing, ", "))
+	case len(conflicted) > 0:
+		return fmt.Errorf("merge conflicts to resolve in: %s", strings.Join(conflicted, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentLintCommand creates the agent definition linting command
+func NewAgentLintCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "lint [path...]",
+		Short: "Validate agent definition files",
+		Long: `Validate agent markdown files: frontmatter schema, mode, temperature range,
+tool names, model format, required sections and template directives, which are
+expanded as when the agent is loaded to find unset variables and missing includes.
+Paths may be files or directories. Without a path, installed agents in both scopes are checked.
+Exits with a nonzero status when any error is found.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runLint(args, jsonOutput)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print diagnostics as JSON")
+
+	return cmd
+}
+
+// runLint lints the given paths and reports diagnostics
+func runLint(paths []string, jsonOutput bool) error {
+	if len(paths) == 0 {
+		for _, scope := range []config.Scope{config.UserScope, config.ProjectScope} {
+			agentDir, err := config.GetAgentDir(scope)
+			if err != nil {
+				continue
+			}
+			if _, err := os.Stat(agentDir); err == nil {
+				paths = append(paths, agentDir)
+			}
+		}
+	}
+
+	diags := []lint.Diagnostic{}
+	for _, path := range paths {
+		pathDiags, err := lint.LintPath(path)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, pathDiags...)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diagnostics: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printDiagnostics(paths, diags)
+	}
+
+	if lint.HasErrors(diags) {
+		return fmt.Errorf("agent lint failed")
+	}
+	return nil
+}
+
+// printDiagnostics prints human-readable diagnostics with a summary
+func printDiagnostics(paths []string, diags []lint.Diagnostic) {
+	if len(paths) == 0 {
+		fmt.Println("No installed agents to lint.")
+		return
+	}
+
+	errors, warnings := 0, 0
+	for _, d := range diags {
+		fmt.Println(d.String())
+		if d.Severity == lint.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	if len(diags) == 0 {
+		fmt.Println("✓ No problems found")
+		return
+	}
+	fmt.Printf("\n%d errors, %d warnings\n", errors, warnings)
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentListCommand creates the non-interactive agent list command
+func NewAgentListCommand() *cobra.Command {
+	var scopeName, output string
+	var installed bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List available or installed agents",
+		Long: `List the agents that can be executed, built-in and custom, with the scopes
+they are installed in. With --installed, list the agent files installed in
+each scope instead, along with their version and whether they were modified.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			scopes := []config.Scope{config.UserScope, config.ProjectScope}
+			if scopeName != "" {
+				scope, err := config.ParseScope(scopeName)
+				if err != nil {
+					return err
+				}
+				scopes = []config.Scope{scope}
+			}
+
+			if installed && output != outputTable {
+				return printInstalledStates(scopes, output)
+			}
+			if installed {
+				fmt.Println("=== Installed Agents ===")
+				count, err := printInstalledAgents(scopes)
+				if err != nil {
+					return err
+				}
+				if count == 0 {
+					fmt.Println("No agents installed.")
+				}
+				return nil
+			}
+			return listAvailableAgents(scopes, output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&installed, "installed", false, "list installed agents only")
+	cmd.Flags().StringVar(&scopeName, "scope", "", "only show this scope (user or project)")
+	cmd.RegisterFlagCompletionFunc("scope", completeScopes)
+	addOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+// listAvailableAgents prints every agent the engine can resolve and the
+// scopes it is installed in
+func listAvailableAgents(scopes []config.Scope, format string) error {
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return err
+	}
+
+	agents, skipped, err := engine.ListAvailableAgentsWithErrors()
+	if err != nil {
+		return err
+	}
+	warnSkipped(skipped)
+	if format != outputTable {
+		return printStructured(format, agents)
+	}
+
+	status, err := installStatus(scopes)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("=== Available Agents ===")
+	for _, a := range agents {
+		note := ""
+		if scopes := status[a.Name]; len(scopes) > 0 {
+			note = fmt.Sprintf(" (installed: %s)", strings.Join(scopes, ", "))
+		}
+		fmt.Printf("  • %s - %s%s\n", a.Name, a.Description, note)
+	}
+	return nil
+}
+
+// warnSkipped reports agents or workflows that could not be loaded. It
+// writes to stderr so structured output on stdout stays parseable.
+func warnSkipped(skipped []error) {
+	for _, err := range skipped {
+		fmt.Fprintf(os.Stderr, "⚠ skipped: %v\n", err)
+	}
+}
+
+// printInstalledStates prints the install state of every agent in scopes
+// as JSON or YAML
+func printInstalledStates(scopes []config.Scope, format string) error {
+	states := []config.AgentState{}
+	for _, scope := range scopes {
+		agents, err := config.GetInstalledAgents(scope)
+		if err != nil {
+			return fmt.Errorf("failed to load %s agents: %w", scope, err)
+		}
+		states = append(states, agents...)
+	}
+	return printStructured(format, states)
+}
+
+// installStatus maps agent names to the scopes they are installed in,
+// noting files that were modified or are missing
+func installStatus(scopes []config.Scope) (map[string][]string, error) {
+	status := make(map[string][]string)
+	for _, scope := range scopes {
+		agents, err := config.GetInstalledAgents(scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s agents: %w", scope, err)
+		}
+
+		for _, a := range agents {
+			entry := string(scope)
+			switch a.Status {
+			case config.StatusModified:
+				entry += " (modified)"
+			case config.StatusMissing:
+				continue
+			}
+			status[a.Name] = append(status[a.Name], entry)
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted by --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// addOutputFlag registers --output on a command that can print structured results
+func addOutputFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVarP(format, "output", "o", outputTable, "output format (table, json or yaml)")
+	cmd.RegisterFlagCompletionFunc("output", completeOutputFormats)
+}
+
+// validateOutput checks an --output value
+func validateOutput(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (must be %s, %s or %s)", format, outputTable, outputJSON, outputYAML)
+	}
+}
+
+// messageWriter returns where prompts and progress go. They are sent to
+// stderr when stdout carries JSON or YAML, so the output stays parseable.
+func messageWriter(format string) io.Writer {
+	if format == outputTable {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// printStructured writes v to stdout as JSON or YAML. YAML output uses the
+// same field names as JSON, taken from the json struct tags.
+func printStructured(format string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	if format == outputJSON {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	// JSON is valid YAML; decoding it into a node keeps the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to convert output to YAML: %w", err)
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	fmt.Print(buf.String())
+	return nil
+}
+
+// resetStyle switches nodes decoded from JSON to block style, writing
+// multi-line strings as literal blocks
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentRollbackCommand creates the command that undoes agent installs
+func NewAgentRollbackCommand() *cobra.Command {
+	var scopeName, id string
+	var list, force bool
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore agents from the last install backup",
+		Long: `Undo an agent install by restoring the files it replaced from its
+backup-<timestamp> directory and removing the files it added.
+The most recent backup is restored unless --id is given. If agent files were
+edited since the install, nothing is restored unless --force is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			scope, err := config.ParseScope(scopeName)
+			if err != nil {
+				return err
+			}
+
+			if list {
+				return listBackups(scope)
+			}
+			return rollbackInstall(scope, id, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&scopeName, "scope", "", "scope to restore (user or project)")
+	cmd.Flags().StringVar(&id, "id", "", "backup to restore instead of the most recent one")
+	cmd.Flags().BoolVar(&list, "list", false, "list available backups")
+	cmd.Flags().BoolVar(&force, "force", false, "discard changes made to agent files since the install")
+	cmd.MarkFlagRequired("scope")
+	cmd.RegisterFlagCompletionFunc("scope", completeScopes)
+	cmd.RegisterFlagCompletionFunc("id", completeBackups)
+
+	return cmd
+}
+
+// listBackups prints the install backups of a scope
+func listBackups(scope config.Scope) error {
+	backups, err := installer.ListBackups(scope)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== Backups (%s scope) ===\n", scope)
+	if len(backups) == 0 {
+		fmt.Println("No backups.")
+		return nil
+	}
+	for _, backup := range backups {
+		fmt.Printf("  %s  %s  %s\n", backup.ID, backup.Time.Local().Format("2006-01-02 15:04"), strings.Join(backup.Agents, ", "))
+	}
+	return nil
+}
+
+// rollbackInstall restores a backup and reports what changed
+func rollbackInstall(scope config.Scope, id string, force bool) error {
+	backup, err := installer.Rollback(scope, id, force)
+	if errors.Is(err, installer.ErrModified) {
+		return fmt.Errorf("%w\nKeep a copy of your changes, or run again with --force to discard them", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Rolled back install %s in %s scope\n", backup.ID, scope)
+	fmt.Printf("  Restored %d files, removed %d files\n", len(backup.Replaced), len(backup.Added))
+	return nil
+}
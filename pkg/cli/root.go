@@ -19,6 +19,9 @@ It can install agents, commands, MCP servers, and skills to either user or proje
 		NewCommandCommand(),
 		NewMCPCommand(),
 		NewSkillCommand(),
+		NewInitCommand(),
+		NewConfigCommand(),
+		NewDoctorCommand(),
 	)
 
 	return cmd
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/session"
+	"github.com/spf13/cobra"
+)
+
+// NewSessionCommand creates the session management command
+func NewSessionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Manage agent conversation sessions",
+		Long:  "List, show and delete conversation sessions created with 'commands execute --session'",
+	}
+
+	var listOutput, showOutput string
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List saved sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(listOutput); err != nil {
+				return err
+			}
+			return listSessions(listOutput)
+		},
+	}
+	addOutputFlag(listCmd, &listOutput)
+
+	showCmd := &cobra.Command{
+		Use:               "show [session-name]",
+		Short:             "Show the message history of a session",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSession,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(showOutput); err != nil {
+				return err
+			}
+			return showSession(args[0], showOutput)
+		},
+	}
+	addOutputFlag(showCmd, &showOutput)
+
+	cmd.AddCommand(
+		listCmd,
+		showCmd,
+		&cobra.Command{
+			Use:               "delete [session-name...]",
+			Short:             "Delete one or more sessions",
+			Args:              cobra.MinimumNArgs(1),
+			ValidArgsFunction: completeSessions,
+			Run: func(cmd *cobra.Command, args []string) {
+				deleteSessions(args)
+			},
+		},
+	)
+
+	return cmd
+}
+
+// sessionStore returns the store the agent engine saves sessions in
+func sessionStore() (*session.Store, error) {
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return nil, err
+	}
+	if engine.Sessions() == nil {
+		return nil, errors.New("session storage is not available")
+	}
+	return engine.Sessions(), nil
+}
+
+// listSessions shows all saved sessions
+func listSessions(format string) error {
+	store, err := sessionStore()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	if format != outputTable {
+		if sessions == nil {
+			sessions = []session.Session{}
+		}
+		return printStructured(format, sessions)
+	}
+
+	fmt.Println("\n=== Sessions ===")
+	if len(sessions) == 0 {
+		fmt.Println("No sessions found.")
+		fmt.Println("Use 'commands execute <agent> --session <name>' to start one.")
+		return nil
+	}
+
+	for _, sess := range sessions {
+		fmt.Printf("• %s - %s agent, %d messages (updated %s)\n",
+			sess.Name, sess.AgentName, len(sess.Messages), sess.Updated.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// showSession prints the message history of a session
+func showSession(name, format string) error {
+	store, err := sessionStore()
+	if err != nil {
+		return err
+	}
+
+	sess, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+
+	if format != outputTable {
+		return printStructured(format, sess)
+	}
+
+	fmt.Printf("\n=== Session %s ===\n", sess.Name)
+	fmt.Printf("Agent: %s\n", sess.AgentName)
+	fmt.Printf("Created: %s\n", sess.Created.Format("2006-01-02 15:04"))
+	fmt.Printf("Updated: %s\n", sess.Updated.Format("2006-01-02 15:04"))
+
+	for _, msg := range sess.Messages {
+		fmt.Printf("\n--- %s (%s) ---\n%s\n", msg.Role, msg.Timestamp.Format("15:04:05"), msg.Content)
+	}
+	return nil
+}
+
+// deleteSessions removes the named sessions
+func deleteSessions(names []string) {
+	store, err := sessionStore()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	for _, name := range names {
+		if err := store.Delete(name); err != nil {
+			fmt.Printf("Error deleting %s: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("✓ Deleted session %s\n", name)
+	}
+}
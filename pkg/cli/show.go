@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentShowCommand creates the command that prints an agent definition
+func NewAgentShowCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show an agent's settings and prompt",
+		Long: `Show the resolved definition of an agent: its description, mode, model,
+temperature and tools, where it was resolved from, the scopes it is installed
+in, and its prompt content.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAgents,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+			return showAgent(args[0], output)
+		},
+	}
+
+	addOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+// showAgent prints a resolved agent
+func showAgent(name, format string) error {
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return err
+	}
+
+	res, err := engine.ResolveAgent(name)
+	if err != nil {
+		return err
+	}
+	if format != outputTable {
+		return printStructured(format, res.Agent)
+	}
+
+	status, err := installStatus([]config.Scope{config.UserScope, config.ProjectScope})
+	if err != nil {
+		return err
+	}
+
+	a := res.Agent
+	model := a.Model
+	if model == "" {
+		model = installer.DefaultModel + " (default)"
+	}
+
+	var tools []string
+	for tool, enabled := range a.Tools {
+		if enabled {
+			tools = append(tools, tool)
+		}
+	}
+	sort.Strings(tools)
+
+	installed := "no"
+	if scopes := status[a.Name]; len(scopes) > 0 {
+		installed = strings.Join(scopes, ", ")
+	}
+
+	winner := res.Winner()
+	fmt.Printf("=== Agent: %s ===\n", a.Name)
+	fmt.Printf("Description: %s\n", a.Description)
+	fmt.Printf("Mode: %s\n", a.Mode)
+	fmt.Printf("Model: %s\n", model)
+	fmt.Printf("Temperature: %.2f\n", a.Temperature)
+	fmt.Printf("Tools: %s\n", strings.Join(tools, ", "))
+	fmt.Printf("Resolved from: %s (%s)\n", winner.Path, winner.Source)
+	fmt.Printf("Installed: %s\n", installed)
+
+	fmt.Println("\n--- Prompt ---")
+	fmt.Print(a.Content)
+	if !strings.HasSuffix(a.Content, "\n") {
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentUninstallCommand creates the non-interactive agent uninstall command
+func NewAgentUninstallCommand() *cobra.Command {
+	var scopeName string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "uninstall <name...>",
+		Short: "Remove installed agents from a scope",
+		Long: `Remove agents installed by opencode-setup from the user or project scope.
+Files that were not installed by this tool are never removed. Agent files that
+have been modified since installation are kept unless --force is given.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeInstalledAgents,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			scope, err := config.ParseScope(scopeName)
+			if err != nil {
+				return err
+			}
+
+			removed := uninstallAgents(args, scope, func(string) bool { return force })
+			if removed < len(args) {
+				return fmt.Errorf("removed %d of %d agents", removed, len(args))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&scopeName, "scope", "", "scope to remove agents from (user or project)")
+	cmd.Flags().BoolVar(&force, "force", false, "remove agents even if they have local modifications")
+	cmd.MarkFlagRequired("scope")
+	cmd.RegisterFlagCompletionFunc("scope", completeScopes)
+
+	return cmd
+}
+
+// uninstallAgents removes the named agents from scope, reporting each result.
+// forceModified decides whether an agent with local modifications is removed.
+// It returns the number of agents removed.
+func uninstallAgents(names []string, scope config.Scope, forceModified func(name string) bool) int {
+	removed := 0
+	for _, name := range names {
+		err := installer.UninstallAgent(name, scope, false)
+		if errors.Is(err, installer.ErrModified) && forceModified(name) {
+			err = installer.UninstallAgent(name, scope, true)
+		}
+
+		switch {
+		case err == nil:
+			fmt.Printf("✓ Removed %s from %s scope\n", name, scope)
+			removed++
+		case errors.Is(err, installer.ErrModified):
+			fmt.Printf("✗ %v; use --force to remove it anyway\n", err)
+		default:
+			fmt.Printf("✗ %v\n", err)
+		}
+	}
+	return removed
+}
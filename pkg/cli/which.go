@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/frontmatter"
+	"github.com/spf13/cobra"
+)
+
+// NewAgentWhichCommand creates the command that explains agent resolution
+func NewAgentWhichCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which <name>",
+		Short: "Show which file defines an agent and the merged result",
+		Long: `Show where an agent is resolved from. Sources are searched in the configured
+resolution order (default: project > user > embedded). Override files and the
+agents it extends are listed in the order they are applied, followed by the
+merged agent definition.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAgents,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return showAgentResolution(args[0])
+		},
+	}
+
+	return cmd
+}
+
+// showAgentResolution prints the files that make up an agent
+func showAgentResolution(name string) error {
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return err
+	}
+
+	res, err := engine.ResolveAgent(name)
+	if err != nil {
+		return err
+	}
+
+	winner := res.Winner()
+	fmt.Printf("=== Agent: %s ===\n", res.Agent.Name)
+	fmt.Printf("Resolution order: %s\n", strings.Join(res.Order, " > "))
+	fmt.Printf("Resolved from: %s (%s)\n", winner.Path, winner.Source)
+
+	fmt.Println("\nFiles (applied in order):")
+	for i, layer := range res.Layers {
+		note := ""
+		switch {
+		case layer.Override:
+			note = " [override]"
+		case layer.Agent != res.Agent.Name:
+			note = " [extended]"
+		}
+		fmt.Printf("  %d. %-10s %s%s\n", i+1, layer.Source, layer.Path, note)
+	}
+
+	merged, err := frontmatter.Render(res.Agent.Frontmatter(), res.Agent.Content)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n--- Merged definition ---")
+	fmt.Print(merged)
+
+	return nil
+}
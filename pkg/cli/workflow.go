@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/interactive"
+	"github.com/gsmlg-dev/open-code-agents/pkg/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+// NewWorkflowRunCommand creates the non-interactive workflow command
+func NewWorkflowRunCommand() *cobra.Command {
+	var sets, setFiles []string
+	var contextFile, output string
+
+	cmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run a workflow without prompts",
+		Long: `Run a workflow with its context given on the command line.
+
+Context values come from a YAML or JSON file (--context-file), then from files
+(--set-file key=path) and finally from --set key=value, later sources
+overriding earlier ones. A path of "-" reads from stdin. Every {{placeholder}}
+used by the workflow's steps must be given a value.`,
+		Example: `  opencode-setup commands workflow run bug-fix --set-file bug_description=issue.md
+  opencode-setup commands workflow run new-feature --set feature_description="OAuth login, with PKCE"
+  gh issue view 42 | opencode-setup commands workflow run bug-fix --set-file bug_description=- -o json`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorkflows,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+
+			workflow, err := orchestrator.GetWorkflow(args[0])
+			if err != nil {
+				return err
+			}
+
+			workflowContext, err := buildWorkflowContext(contextFile, setFiles, sets)
+			if err != nil {
+				return err
+			}
+
+			if missing := workflow.MissingContext(workflowContext); len(missing) > 0 {
+				return fmt.Errorf("missing context for %s workflow: %s (use --set %s=...)", workflow.Name, strings.Join(missing, ", "), missing[0])
+			}
+
+			fmt.Fprintf(messageWriter(output), "Executing %s workflow...\n", workflow.Name)
+			return executeWorkflow(workflow, workflowContext, output)
+		},
+	}
+
+	// StringArray keeps commas inside values, unlike StringSlice
+	cmd.Flags().StringArrayVar(&sets, "set", nil, "set a context value (key=value, repeatable)")
+	cmd.Flags().StringArrayVar(&setFiles, "set-file", nil, "set a context value from a file or - for stdin (key=path, repeatable)")
+	cmd.Flags().StringVar(&contextFile, "context-file", "", "YAML or JSON file of context values, or - for stdin")
+	cmd.RegisterFlagCompletionFunc("set", completeContextKeys)
+	cmd.RegisterFlagCompletionFunc("set-file", completeContextKeys)
+	addOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+// buildWorkflowContext merges the context file, --set-file and --set values
+func buildWorkflowContext(contextFile string, setFiles, sets []string) (map[string]string, error) {
+	workflowContext := make(map[string]string)
+	stdinUsed := false
+
+	read := func(path string) ([]byte, error) {
+		if path != "-" {
+			return os.ReadFile(path)
+		}
+		if stdinUsed {
+			return nil, errors.New("stdin can only be read once")
+		}
+		stdinUsed = true
+		return io.ReadAll(interactive.Stdin)
+	}
+
+	if contextFile != "" {
+		data, err := read(contextFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read context file: %w", err)
+		}
+		values, err := orchestrator.ParseContext(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", contextFile, err)
+		}
+		for key, value := range values {
+			workflowContext[key] = value
+		}
+	}
+
+	for _, set := range setFiles {
+		key, path, err := splitAssignment("--set-file", set)
+		if err != nil {
+			return nil, err
+		}
+		data, err := read(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read value of %s: %w", key, err)
+		}
+		workflowContext[key] = strings.TrimRight(string(data), "\r\n")
+	}
+
+	for _, set := range sets {
+		key, value, err := splitAssignment("--set", set)
+		if err != nil {
+			return nil, err
+		}
+		workflowContext[key] = value
+	}
+
+	return workflowContext, nil
+}
+
+// splitAssignment splits a key=value flag value
+func splitAssignment(flag, s string) (string, string, error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid %s %q: expected key=value", flag, s)
+	}
+	return key, value, nil
+}
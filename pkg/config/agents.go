@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -16,13 +17,65 @@ const (
 	ProjectScope Scope = "project"
 )
 
+// ParseScope converts a scope name such as "user" or "project" to a Scope
+func ParseScope(name string) (Scope, error) {
+	switch Scope(name) {
+	case UserScope, ProjectScope:
+		return Scope(name), nil
+	default:
+		return "", fmt.Errorf("unknown scope %q (must be %s or %s)", name, UserScope, ProjectScope)
+	}
+}
+
+// SourceEmbedded names the agents built into the binary in a resolution order
+const SourceEmbedded = "embedded"
+
+// DefaultAgentResolution returns the default agent source precedence:
+// project agents override user agents, which override embedded agents
+func DefaultAgentResolution() []string {
+	return []string{string(ProjectScope), string(UserScope), SourceEmbedded}
+}
+
+// ValidateAgentResolution checks that an agent resolution order names only
+// known sources, each at most once
+func ValidateAgentResolution(order []string) error {
+	if len(order) == 0 {
+		return fmt.Errorf("agent resolution order is empty")
+	}
+
+	seen := make(map[string]bool)
+	for _, source := range order {
+		switch source {
+		case string(ProjectScope), string(UserScope), SourceEmbedded:
+		default:
+			return fmt.Errorf("unknown agent source %q (must be %s, %s or %s)", source, ProjectScope, UserScope, SourceEmbedded)
+		}
+		if seen[source] {
+			return fmt.Errorf("agent source %q listed more than once", source)
+		}
+		seen[source] = true
+	}
+	return nil
+}
+
+// Installation status of an agent, as compared with its lock entry
+const (
+	StatusInstalled = "installed" // File matches the hash recorded at install time
+	StatusModified  = "modified"  // File was changed after it was installed
+	StatusUnmanaged = "unmanaged" // File was not installed by this tool
+	StatusMissing   = "missing"   // Lock entry exists but the file was removed
+)
+
 // AgentState represents the state of an installed agent
 type AgentState struct {
 	Name      string    `json:"name"`
-	Version   string    `json:"version"`
+	Version   string    `json:"version,omitempty"`
+	Source    string    `json:"source,omitempty"`
 	Scope     Scope     `json:"scope"`
 	Installed time.Time `json:"installed"`
 	Path      string    `json:"path"`
+	Hash      string    `json:"hash,omitempty"` // Hash recorded at install time
+	Status    string    `json:"status"`
 }
 
 // GetConfigPath returns the configuration path for a given scope
@@ -54,25 +107,55 @@ func GetAgentDir(scope Scope) (string, error) {
 	return filepath.Join(configPath, "agent"), nil
 }
 
-// GetInstalledAgents returns list of installed agents for a given scope
+// GetAgentBaseDir returns the directory holding the last installed version
+// of each agent, used as the base for three-way merges
+func GetAgentBaseDir(scope Scope) (string, error) {
+	configPath, err := GetConfigPath(scope)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configPath, "agent-base"), nil
+}
+
+// GetWorkflowDir returns the directory of user-defined workflows for a given scope
+func GetWorkflowDir(scope Scope) (string, error) {
+	configPath, err := GetConfigPath(scope)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configPath, "workflows"), nil
+}
+
+// GetSessionDir returns the session directory for a given scope
+func GetSessionDir(scope Scope) (string, error) {
+	configPath, err := GetConfigPath(scope)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configPath, "sessions"), nil
+}
+
+// GetInstalledAgents returns the agent files in a scope, using the scope's
+// lock file for versions and install times and to detect local changes
 func GetInstalledAgents(scope Scope) ([]AgentState, error) {
 	agentDir, err := GetAgentDir(scope)
 	if err != nil {
 		return nil, err
 	}
 
-	var agents []AgentState
-
-	// Check if directory exists
-	if _, err := os.Stat(agentDir); os.IsNotExist(err) {
-		return agents, nil
+	lock, err := LoadLockFile(scope)
+	if err != nil {
+		return nil, err
 	}
 
+	var agents []AgentState
+
 	entries, err := os.ReadDir(agentDir)
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read agent directory: %w", err)
 	}
 
+	found := make(map[string]bool)
 	for _, entry := range entries {
 		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
 			continue
@@ -84,15 +167,57 @@ func GetInstalledAgents(scope Scope) ([]AgentState, error) {
 		}
 
 		name := strings.TrimSuffix(entry.Name(), ".md")
-		agents = append(agents, AgentState{
+		path := filepath.Join(agentDir, entry.Name())
+		found[name] = true
+
+		state := AgentState{
 			Name:      name,
-			Version:   "1.0.0",
 			Scope:     scope,
 			Installed: info.ModTime(),
-			Path:      filepath.Join(agentDir, entry.Name()),
+			Path:      path,
+			Status:    StatusUnmanaged,
+		}
+
+		if lockEntry, ok := lock.Agents[name]; ok {
+			state.Version = lockEntry.Version
+			state.Source = lockEntry.Source
+			state.Installed = lockEntry.Installed
+			state.Hash = lockEntry.Hash
+			state.Status = StatusInstalled
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read agent file: %w", err)
+			}
+			if HashContent(content) != lockEntry.Hash {
+				state.Status = StatusModified
+			}
+		}
+
+		agents = append(agents, state)
+	}
+
+	// Agents recorded as installed whose files have since been deleted
+	for name, lockEntry := range lock.Agents {
+		if found[name] {
+			continue
+		}
+		agents = append(agents, AgentState{
+			Name:      name,
+			Version:   lockEntry.Version,
+			Source:    lockEntry.Source,
+			Scope:     scope,
+			Installed: lockEntry.Installed,
+			Path:      filepath.Join(agentDir, name+".md"),
+			Hash:      lockEntry.Hash,
+			Status:    StatusMissing,
 		})
 	}
 
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].Name < agents[j].Name
+	})
+
 	return agents, nil
 }
 
@@ -103,7 +228,7 @@ func IsAgentInstalled(agentName string) bool {
 
 	allAgents := append(userAgents, projectAgents...)
 	for _, agent := range allAgents {
-		if agent.Name == agentName {
+		if agent.Name == agentName && agent.Status != StatusMissing {
 			return true
 		}
 	}
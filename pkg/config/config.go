@@ -9,9 +9,16 @@ import (
 
 // Config represents the application configuration
 type Config struct {
+	// Schema is the JSON Schema editors validate the file against
+	Schema string `json:"$schema,omitempty" config:"-"`
+	// Version is the schema version of the file; older files are migrated
+	Version int `json:"version" config:"-"`
+
 	DefaultAgent string            `json:"default_agent,omitempty"`
 	Workflows    map[string]string `json:"workflows,omitempty"`
-	Settings     Settings          `json:"settings"`
+	// PromptVars are variables available to agent content as {{.Vars.name}}
+	PromptVars map[string]string `json:"prompt_vars,omitempty"`
+	Settings   Settings          `json:"settings"`
 }
 
 // Settings contains application-wide settings
@@ -20,54 +27,58 @@ type Settings struct {
 	AutoSave   bool   `json:"auto_save"`
 	ShowHints  bool   `json:"show_hints"`
 	MaxHistory int    `json:"max_history"`
+
+	// CompactionModel is the model used to summarise older conversation turns
+	CompactionModel string `json:"compaction_model"`
+	// CompactionThreshold is the estimated token count that triggers compaction (0 disables it)
+	CompactionThreshold int `json:"compaction_threshold"`
+
+	// AgentResolution lists the agent sources from highest to lowest precedence
+	AgentResolution []string `json:"agent_resolution"`
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Version:   CurrentVersion,
 		Workflows: make(map[string]string),
 		Settings: Settings{
-			LogLevel:   "info",
-			AutoSave:   true,
-			ShowHints:  true,
-			MaxHistory: 100,
+			LogLevel:            "info",
+			AutoSave:            true,
+			ShowHints:           true,
+			MaxHistory:          100,
+			CompactionModel:     "anthropic/claude-3-5-haiku-20241022",
+			CompactionThreshold: 100000,
+			AgentResolution:     DefaultAgentResolution(),
 		},
 	}
 }
 
-// LoadConfig loads configuration from file
+// LoadConfig loads configuration from file. Files written for an older
+// schema version are migrated in memory; see Migrate.
 func LoadConfig(scope Scope) (*Config, error) {
-	configPath, err := getConfigFilePath(scope)
+	raw, _, err := readRaw(scope)
 	if err != nil {
 		return nil, err
 	}
 
 	// If config doesn't exist, return default
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	if raw == nil {
 		return DefaultConfig(), nil
 	}
 
-	data, err := os.ReadFile(configPath)
+	// Start from defaults so settings missing from the file keep their default values
+	config, err := decodeRaw(raw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	// Ensure workflows map exists
-	if config.Workflows == nil {
-		config.Workflows = make(map[string]string)
-	}
-
-	return &config, nil
+	return config, nil
 }
 
 // SaveConfig saves configuration to file
 func SaveConfig(config *Config, scope Scope) error {
-	configPath, err := getConfigFilePath(scope)
+	configPath, err := GetConfigFilePath(scope)
 	if err != nil {
 		return err
 	}
@@ -83,6 +94,9 @@ func SaveConfig(config *Config, scope Scope) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
+	if err := backupOutdated(configPath); err != nil {
+		return err
+	}
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
@@ -90,8 +104,8 @@ func SaveConfig(config *Config, scope Scope) error {
 	return nil
 }
 
-// getConfigFilePath returns the path to the config file for a given scope
-func getConfigFilePath(scope Scope) (string, error) {
+// GetConfigFilePath returns the path to the config file for a given scope
+func GetConfigFilePath(scope Scope) (string, error) {
 	configPath, err := GetConfigPath(scope)
 	if err != nil {
 		return "", err
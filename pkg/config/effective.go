@@ -0,0 +1,548 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Origins of effective config values, from lowest to highest precedence
+const (
+	OriginDefault = "default"
+	OriginUser    = "user"
+	OriginProject = "project"
+	OriginEnv     = "env"
+)
+
+// EnvPrefix starts the environment variables that override config values.
+// The rest of the name is the key in upper case with dots replaced by
+// underscores: OPENCODE_SETTINGS_LOG_LEVEL sets settings.log_level and
+// OPENCODE_PROMPT_VARS_TEAM sets prompt_vars.team.
+const EnvPrefix = "OPENCODE_"
+
+// Kinds of config keys
+const (
+	KindString = "string"
+	KindBool   = "bool"
+	KindInt    = "int"
+	KindList   = "list" // Comma-separated on the command line and in the environment
+	KindMap    = "map"  // String entries, set as <key>.<name>
+)
+
+// Key describes a config key in dotted form, such as settings.log_level
+type Key struct {
+	Name string
+	Kind string
+	path []string // JSON object path of the key
+}
+
+// EnvVar returns the environment variable that overrides the key. For a
+// map key it is the prefix of the variables that set its entries.
+func (k Key) EnvVar() string {
+	name := EnvPrefix + strings.ToUpper(strings.ReplaceAll(k.Name, ".", "_"))
+	if k.Kind == KindMap {
+		name += "_"
+	}
+	return name
+}
+
+// Keys returns the known config keys sorted by name
+func Keys() []Key {
+	keys := structKeys(reflect.TypeOf(Config{}), nil)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// structKeys lists the keys of the JSON fields of a struct type
+func structKeys(t reflect.Type, parent []string) []Key {
+	var keys []Key
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || field.Tag.Get("config") == "-" {
+			continue
+		}
+		path := append(append([]string{}, parent...), name)
+
+		var kind string
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, structKeys(field.Type, path)...)
+			continue
+		case reflect.String:
+			kind = KindString
+		case reflect.Bool:
+			kind = KindBool
+		case reflect.Int:
+			kind = KindInt
+		case reflect.Slice:
+			kind = KindList
+		case reflect.Map:
+			kind = KindMap
+		default:
+			continue
+		}
+		keys = append(keys, Key{Name: strings.Join(path, "."), Kind: kind, path: path})
+	}
+	return keys
+}
+
+// LookupKey returns the key with the given name. Entries of map keys are
+// named <key>.<entry>, such as prompt_vars.team, and hold strings.
+func LookupKey(name string) (Key, error) {
+	for _, key := range Keys() {
+		if key.Name == name {
+			return key, nil
+		}
+		if key.Kind == KindMap && strings.HasPrefix(name, key.Name+".") && len(name) > len(key.Name)+1 {
+			entry := strings.TrimPrefix(name, key.Name+".")
+			path := append(append([]string{}, key.path...), entry)
+			return Key{Name: name, Kind: KindString, path: path}, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown config key %q", name)
+}
+
+// ParseValue converts a value given on the command line or in the
+// environment to the type of the key
+func ParseValue(key Key, value string) (interface{}, error) {
+	switch key.Kind {
+	case KindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", key.Name, value)
+		}
+		return b, nil
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, got %q", key.Name, value)
+		}
+		return json.Number(strconv.Itoa(n)), nil
+	case KindList:
+		list := []interface{}{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	case KindMap:
+		return nil, fmt.Errorf("%s is a map; set its entries as %s.<name>", key.Name, key.Name)
+	default:
+		return value, nil
+	}
+}
+
+// FormatValue renders a config value the way it is given to ParseValue
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Value is an effective config value and the layer that set it
+type Value struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Origin string      `json:"origin"`
+	// Source is the config file or environment variable the value came from
+	Source string `json:"source,omitempty"`
+}
+
+// Effective is the configuration merged from the defaults, the user and
+// project config files and OPENCODE_* environment variables, each layer
+// overriding the values set by the ones before it
+type Effective struct {
+	Config *Config
+	Values []Value // Sorted by key
+}
+
+// LoadEffective merges the configuration layers. Objects such as settings
+// and prompt_vars are merged key by key, so a project config that sets one
+// setting keeps the others from the user config.
+func LoadEffective() (*Effective, error) {
+	values := make(map[string]Value)
+	set := func(leaves map[string]interface{}, origin, source string) {
+		for key, v := range leaves {
+			if _, err := LookupKey(key); err != nil {
+				continue // Unknown keys are ignored, as LoadConfig does
+			}
+			values[key] = Value{Key: key, Value: v, Origin: origin, Source: source}
+		}
+	}
+
+	set(defaultValues(), OriginDefault, "")
+
+	for _, scope := range []Scope{UserScope, ProjectScope} {
+		raw, path, err := readRaw(scope)
+		if err != nil {
+			return nil, err
+		}
+		if raw == nil {
+			continue
+		}
+		if _, err := decodeRaw(raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		leaves := make(map[string]interface{})
+		flatten(raw, "", leaves)
+		set(leaves, string(scope), path)
+	}
+
+	env, err := envValues()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range env {
+		values[v.Key] = v
+	}
+
+	e := &Effective{}
+	merged := make(map[string]interface{})
+	for key, v := range values {
+		k, _ := LookupKey(key)
+		setPath(merged, k.path, v.Value)
+		e.Values = append(e.Values, v)
+	}
+	sort.Slice(e.Values, func(i, j int) bool {
+		return e.Values[i].Key < e.Values[j].Key
+	})
+
+	cfg, err := decodeRaw(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
+	}
+	if err := ValidateAgentResolution(cfg.Settings.AgentResolution); err != nil {
+		return nil, fmt.Errorf("invalid settings.agent_resolution: %w", err)
+	}
+	e.Config = cfg
+	return e, nil
+}
+
+// Get returns the value of a key, or the values under it if the key names
+// an object such as settings or prompt_vars
+func (e *Effective) Get(name string) ([]Value, error) {
+	var found []Value
+	for _, v := range e.Values {
+		if v.Key == name || strings.HasPrefix(v.Key, name+".") {
+			found = append(found, v)
+		}
+	}
+	if len(found) > 0 {
+		return found, nil
+	}
+
+	if _, err := LookupKey(name); err == nil {
+		return nil, fmt.Errorf("%s is not set", name)
+	}
+	for _, key := range Keys() {
+		if strings.HasPrefix(key.Name, name+".") {
+			return nil, fmt.Errorf("%s is not set", name)
+		}
+	}
+	return nil, fmt.Errorf("unknown config key %q", name)
+}
+
+// Set sets a key in a scope's config file, keeping the rest of the file
+func Set(scope Scope, name, value string) error {
+	key, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	v, err := ParseValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	raw, path, err := readRaw(scope)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		raw = map[string]interface{}{"version": CurrentVersion}
+	}
+	if err := setPath(raw, key.path, v); err != nil {
+		return fmt.Errorf("failed to set %s in %s: %w", name, path, err)
+	}
+	if err := validateRaw(raw); err != nil {
+		return err
+	}
+	return writeRaw(path, raw)
+}
+
+// Unset removes a key from a scope's config file, so that lower layers
+// provide its value. It reports whether the key was set.
+func Unset(scope Scope, name string) (bool, error) {
+	key, err := LookupKey(name)
+	if err != nil {
+		return false, err
+	}
+
+	raw, path, err := readRaw(scope)
+	if err != nil || raw == nil {
+		return false, err
+	}
+	if !deletePath(raw, key.path) {
+		return false, nil
+	}
+	if err := validateRaw(raw); err != nil {
+		return false, err
+	}
+	return true, writeRaw(path, raw)
+}
+
+// CheckConfigFile validates a scope's config file and returns the keys in
+// it that are not known config keys
+func CheckConfigFile(scope Scope) ([]string, error) {
+	raw, path, err := readRaw(scope)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	if err := validateRaw(raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	leaves := make(map[string]interface{})
+	flatten(raw, "", leaves)
+	var unknown []string
+	for key := range leaves {
+		if _, err := LookupKey(key); err != nil && !isFileField(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// isFileField reports whether name is a top-level field that describes the
+// file rather than configuring anything, such as version
+func isFileField(name string) bool {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("config") == "-" && strings.Split(field.Tag.Get("json"), ",")[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValues returns the leaves of the default configuration, including
+// keys left out of its JSON because they are empty
+func defaultValues() map[string]interface{} {
+	data, _ := json.Marshal(DefaultConfig())
+	var raw map[string]interface{}
+	decodeJSON(data, &raw)
+
+	leaves := make(map[string]interface{})
+	flatten(raw, "", leaves)
+	for _, key := range Keys() {
+		if _, ok := leaves[key.Name]; ok || key.Kind == KindMap {
+			continue
+		}
+		switch key.Kind {
+		case KindBool:
+			leaves[key.Name] = false
+		case KindInt:
+			leaves[key.Name] = json.Number("0")
+		case KindList:
+			leaves[key.Name] = []interface{}{}
+		default:
+			leaves[key.Name] = ""
+		}
+	}
+	return leaves
+}
+
+// envValues returns the values set by OPENCODE_* environment variables.
+// Empty variables are ignored.
+func envValues() ([]Value, error) {
+	var values []Value
+	for _, key := range Keys() {
+		if key.Kind != KindMap {
+			env := key.EnvVar()
+			s := os.Getenv(env)
+			if s == "" {
+				continue
+			}
+			v, err := ParseValue(key, s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", env, err)
+			}
+			values = append(values, Value{Key: key.Name, Value: v, Origin: OriginEnv, Source: env})
+			continue
+		}
+
+		prefix := key.EnvVar()
+		for _, kv := range os.Environ() {
+			env, s, _ := strings.Cut(kv, "=")
+			if !strings.HasPrefix(env, prefix) || len(env) == len(prefix) || s == "" {
+				continue
+			}
+			entry := key.Name + "." + strings.ToLower(strings.TrimPrefix(env, prefix))
+			values = append(values, Value{Key: entry, Value: s, Origin: OriginEnv, Source: env})
+		}
+	}
+	return values, nil
+}
+
+// readRaw reads a scope's config file as JSON objects, so that the keys the
+// file sets can be told apart from defaults. Files written for an older
+// schema version are migrated in memory. A missing file returns nil.
+func readRaw(scope Scope) (map[string]interface{}, string, error) {
+	path, err := GetConfigFilePath(scope)
+	if err != nil {
+		return nil, "", err
+	}
+
+	raw, err := readFile(path)
+	if err != nil || raw == nil {
+		return nil, path, err
+	}
+	if _, err := migrate(raw, migrations); err != nil {
+		return nil, path, fmt.Errorf("failed to migrate config file %s: %w", path, err)
+	}
+	return raw, path, nil
+}
+
+// readFile reads a config file as JSON objects without migrating it. A
+// missing file returns nil.
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := decodeJSON(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+	return raw, nil
+}
+
+// writeRaw writes a config file from its JSON objects
+func writeRaw(path string, raw map[string]interface{}) error {
+	if err := backupOutdated(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// decodeJSON decodes data keeping numbers as written
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// decodeRaw converts JSON objects to a Config, checking the value types
+func decodeRaw(raw map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Workflows == nil {
+		cfg.Workflows = make(map[string]string)
+	}
+	return cfg, nil
+}
+
+// validateRaw checks that the JSON objects of a config file make a valid Config
+func validateRaw(raw map[string]interface{}) error {
+	cfg, err := decodeRaw(raw)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err := ValidateAgentResolution(cfg.Settings.AgentResolution); err != nil {
+		return fmt.Errorf("invalid settings.agent_resolution: %w", err)
+	}
+	return nil
+}
+
+// flatten collects the non-object values of raw keyed by their dotted path
+func flatten(raw map[string]interface{}, prefix string, leaves map[string]interface{}) {
+	for k, v := range raw {
+		if obj, ok := v.(map[string]interface{}); ok {
+			flatten(obj, prefix+k+".", leaves)
+			continue
+		}
+		leaves[prefix+k] = v
+	}
+}
+
+// setPath sets the value at path, creating objects along the way
+func setPath(raw map[string]interface{}, path []string, v interface{}) error {
+	for _, name := range path[:len(path)-1] {
+		next, ok := raw[name].(map[string]interface{})
+		if !ok {
+			if _, exists := raw[name]; exists {
+				return fmt.Errorf("%s is not an object", name)
+			}
+			next = make(map[string]interface{})
+			raw[name] = next
+		}
+		raw = next
+	}
+	raw[path[len(path)-1]] = v
+	return nil
+}
+
+// deletePath removes the value at path and any objects left empty by it.
+// It reports whether the value was there.
+func deletePath(raw map[string]interface{}, path []string) bool {
+	name := path[0]
+	if len(path) == 1 {
+		_, ok := raw[name]
+		delete(raw, name)
+		return ok
+	}
+
+	next, ok := raw[name].(map[string]interface{})
+	if !ok || !deletePath(next, path[1:]) {
+		return false
+	}
+	if len(next) == 0 {
+		delete(raw, name)
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setup gives the test an empty home and project directory
+func setup(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func writeConfig(t *testing.T, scope Scope, content string) string {
+	t.Helper()
+	path, err := GetConfigFilePath(scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadEffective_Layers(t *testing.T) {
+	setup(t)
+	userPath := writeConfig(t, UserScope, `{"settings": {"log_level": "debug", "max_history": 20}, "prompt_vars": {"team": "platform", "lang": "go"}}`)
+	projectPath := writeConfig(t, ProjectScope, `{"settings": {"max_history": 50}, "prompt_vars": {"team": "payments"}}`)
+	t.Setenv("OPENCODE_SETTINGS_SHOW_HINTS", "false")
+	t.Setenv("OPENCODE_PROMPT_VARS_OWNER", "me")
+
+	e, err := LoadEffective()
+	if err != nil {
+		t.Fatalf("LoadEffective() error = %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		origin string
+		source string
+	}{
+		{"settings.auto_save", "true", OriginDefault, ""},
+		{"settings.log_level", "debug", OriginUser, userPath},
+		{"settings.max_history", "50", OriginProject, projectPath},
+		{"settings.show_hints", "false", OriginEnv, "OPENCODE_SETTINGS_SHOW_HINTS"},
+		{"prompt_vars.lang", "go", OriginUser, userPath},
+		{"prompt_vars.team", "payments", OriginProject, projectPath},
+		{"prompt_vars.owner", "me", OriginEnv, "OPENCODE_PROMPT_VARS_OWNER"},
+	}
+	for _, tt := range tests {
+		values, err := e.Get(tt.key)
+		if err != nil {
+			t.Errorf("Get(%q) error = %v", tt.key, err)
+			continue
+		}
+		v := values[0]
+		if FormatValue(v.Value) != tt.value || v.Origin != tt.origin || v.Source != tt.source {
+			t.Errorf("Get(%q) = %v from %s (%s), want %s from %s (%s)", tt.key, v.Value, v.Origin, v.Source, tt.value, tt.origin, tt.source)
+		}
+	}
+
+	s := e.Config.Settings
+	if s.LogLevel != "debug" || s.MaxHistory != 50 || s.ShowHints || !s.AutoSave {
+		t.Errorf("Config.Settings = %+v", s)
+	}
+	if got := e.Config.PromptVars; got["team"] != "payments" || got["lang"] != "go" || got["owner"] != "me" {
+		t.Errorf("Config.PromptVars = %v", got)
+	}
+}
+
+func TestLoadEffective_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		env     map[string]string
+		want    string
+	}{
+		{"invalid JSON", `{"settings": `, nil, "failed to parse config file"},
+		{"wrong type", `{"settings": {"max_history": "many"}}`, nil, "failed to parse config file"},
+		{"invalid env", "", map[string]string{"OPENCODE_SETTINGS_AUTO_SAVE": "maybe"}, "OPENCODE_SETTINGS_AUTO_SAVE"},
+		{"invalid resolution", "", map[string]string{"OPENCODE_SETTINGS_AGENT_RESOLUTION": "user,user"}, "agent_resolution"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t)
+			if tt.project != "" {
+				writeConfig(t, ProjectScope, tt.project)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if _, err := LoadEffective(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadEffective() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetUnset(t *testing.T) {
+	setup(t)
+	path := writeConfig(t, ProjectScope, `{"prompt_vars": {"team": "platform"}, "custom": 1}`)
+
+	if err := Set(ProjectScope, "settings.agent_resolution", "user, embedded"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := Set(ProjectScope, "settings.compaction_threshold", "0"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	cfg, err := LoadConfig(ProjectScope)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := strings.Join(cfg.Settings.AgentResolution, ","); got != "user,embedded" {
+		t.Errorf("AgentResolution = %s, want user,embedded", got)
+	}
+	if cfg.Settings.CompactionThreshold != 0 || cfg.Settings.MaxHistory != 100 {
+		t.Errorf("Settings = %+v, want threshold 0 and default max_history", cfg.Settings)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"custom": 1`) || strings.Contains(string(data), "max_history") {
+		t.Errorf("config file = %s, want unknown keys kept and defaults not written", data)
+	}
+
+	for _, tt := range []struct{ key, value string }{
+		{"settings.max_history", "lots"},
+		{"settings.agent_resolution", "bogus"},
+		{"prompt_vars", "x"},
+		{"nope", "x"},
+	} {
+		if err := Set(ProjectScope, tt.key, tt.value); err == nil {
+			t.Errorf("Set(%q, %q) succeeded, want error", tt.key, tt.value)
+		}
+	}
+
+	removed, err := Unset(ProjectScope, "prompt_vars.team")
+	if err != nil || !removed {
+		t.Fatalf("Unset() = %v, %v, want true", removed, err)
+	}
+	if removed, _ := Unset(ProjectScope, "prompt_vars.team"); removed {
+		t.Error("second Unset() = true, want false")
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "prompt_vars") {
+		t.Errorf("config file = %s, want empty prompt_vars removed", data)
+	}
+}
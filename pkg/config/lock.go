@@ -0,0 +1,120 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockFileName is the manifest of installed agents kept in each scope
+const LockFileName = "agents.lock.json"
+
+// lockFileVersion is the current lock file format
+const lockFileVersion = 1
+
+// LockEntry records how an agent file was installed
+type LockEntry struct {
+	Source    string    `json:"source"`    // Where the agent came from, e.g. "embedded"
+	Version   string    `json:"version"`   // Version of the source at install time
+	Hash      string    `json:"hash"`      // Content hash of the file as installed
+	Installed time.Time `json:"installed"` // Time of installation
+}
+
+// LockFile is the manifest of agents installed in a scope
+type LockFile struct {
+	Version int                  `json:"version"`
+	Agents  map[string]LockEntry `json:"agents"`
+}
+
+// HashContent returns the content hash stored in lock entries
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// GetLockFilePath returns the path of the lock file for a given scope
+func GetLockFilePath(scope Scope) (string, error) {
+	configPath, err := GetConfigPath(scope)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configPath, LockFileName), nil
+}
+
+// LoadLockFile loads the lock file for a scope, returning an empty one if
+// nothing has been installed yet
+func LoadLockFile(scope Scope) (*LockFile, error) {
+	lockPath, err := GetLockFilePath(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &LockFile{Version: lockFileVersion, Agents: make(map[string]LockEntry)}
+
+	data, err := os.ReadFile(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", lockPath, err)
+	}
+	if lock.Agents == nil {
+		lock.Agents = make(map[string]LockEntry)
+	}
+
+	return lock, nil
+}
+
+// SaveLockFile writes the lock file for a scope
+func SaveLockFile(lock *LockFile, scope Scope) error {
+	lockPath, err := GetLockFilePath(scope)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := EncodeLockFile(lock)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(lockPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	return nil
+}
+
+// EncodeLockFile returns the file contents of a lock file
+func EncodeLockFile(lock *LockFile) ([]byte, error) {
+	lock.Version = lockFileVersion
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lock file: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// UpdateLockFile loads the lock file for a scope, applies updates and saves it
+func UpdateLockFile(scope Scope, updates func(*LockFile)) error {
+	lock, err := LoadLockFile(scope)
+	if err != nil {
+		return err
+	}
+
+	updates(lock)
+
+	return SaveLockFile(lock, scope)
+}
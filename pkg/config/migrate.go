@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CurrentVersion is the schema version of config files written by this
+// build. Files without a version were written before versioning and are
+// version 0.
+const CurrentVersion = 1
+
+// Migration upgrades a config file from one schema version to the next
+type Migration struct {
+	From        int
+	Description string
+	// Migrate changes the file's JSON objects in place. The version field
+	// is updated after it returns.
+	Migrate func(raw map[string]interface{}) error
+}
+
+// migrations upgrade config files one version at a time: migrations[i]
+// upgrades version i to i+1. A change to Config or Settings that older
+// files would not decode into, such as a renamed key, bumps CurrentVersion
+// and adds a migration here.
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "add the schema version",
+		Migrate:     func(map[string]interface{}) error { return nil },
+	},
+}
+
+// MigrationResult reports what Migrate did to a scope's config file
+type MigrationResult struct {
+	Scope   Scope    `json:"scope"`
+	Path    string   `json:"path"`
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Applied []string `json:"applied,omitempty"` // Descriptions of the migrations applied
+	Backup  string   `json:"backup,omitempty"`
+}
+
+// Migrate upgrades a scope's config file to CurrentVersion in place,
+// keeping a copy of the old file next to it. It returns nil if the scope
+// has no config file.
+func Migrate(scope Scope) (*MigrationResult, error) {
+	path, err := GetConfigFilePath(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := readFile(path)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{Scope: scope, Path: path, To: CurrentVersion}
+	result.From, err = fileVersion(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	applied, err := migrate(raw, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate config file %s: %w", path, err)
+	}
+	if len(applied) == 0 {
+		return result, nil
+	}
+	for _, m := range applied {
+		result.Applied = append(result.Applied, m.Description)
+	}
+
+	if err := validateRaw(raw); err != nil {
+		return nil, fmt.Errorf("%s after migration: %w", path, err)
+	}
+	if err := writeRaw(path, raw); err != nil {
+		return nil, err
+	}
+	result.Backup = backupPath(path, result.From)
+	return result, nil
+}
+
+// FileVersion returns the schema version of a scope's config file, or
+// CurrentVersion if the scope has no config file
+func FileVersion(scope Scope) (int, error) {
+	path, err := GetConfigFilePath(scope)
+	if err != nil {
+		return 0, err
+	}
+	raw, err := readFile(path)
+	if err != nil || raw == nil {
+		return CurrentVersion, err
+	}
+	return fileVersion(raw)
+}
+
+// migrate applies the migrations that bring raw up to the last version of
+// the registry, returning those applied
+func migrate(raw map[string]interface{}, registry []Migration) ([]Migration, error) {
+	version, err := fileVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+	if version > len(registry) {
+		return nil, fmt.Errorf("config version %d is newer than this build supports (%d); upgrade opencode-setup", version, len(registry))
+	}
+
+	var applied []Migration
+	for _, m := range registry[version:] {
+		if err := m.Migrate(raw); err != nil {
+			return nil, fmt.Errorf("migration from version %d (%s) failed: %w", m.From, m.Description, err)
+		}
+		raw["version"] = json.Number(fmt.Sprint(m.From + 1))
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// fileVersion returns the version field of a config file's JSON objects
+func fileVersion(raw map[string]interface{}) (int, error) {
+	v, ok := raw["version"]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid config version %v", v)
+	}
+	version, err := n.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid config version %v", v)
+	}
+	return int(version), nil
+}
+
+// backupOutdated copies a config file written for an older schema version
+// to <file>.v<version>.bak before it is overwritten. Current and missing
+// files are not copied.
+func backupOutdated(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := decodeJSON(data, &raw); err != nil {
+		return nil // Not parsed, so nothing was migrated
+	}
+	version, err := fileVersion(raw)
+	if err != nil || version >= CurrentVersion {
+		return nil
+	}
+
+	if err := os.WriteFile(backupPath(path, version), data, 0644); err != nil {
+		return fmt.Errorf("failed to back up config file: %w", err)
+	}
+	return nil
+}
+
+// backupPath returns where backupOutdated copies a config file of a version
+func backupPath(path string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, version)
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMigrations_Registry(t *testing.T) {
+	if len(migrations) != CurrentVersion {
+		t.Fatalf("%d migrations, want one per version up to %d", len(migrations), CurrentVersion)
+	}
+	for i, m := range migrations {
+		if m.From != i || m.Description == "" || m.Migrate == nil {
+			t.Errorf("migrations[%d] = %+v, want From %d with a description", i, m, i)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	setup(t)
+	old := `{"prompt_vars": {"team": "platform"}, "settings": {"max_history": 20}}`
+	path := writeConfig(t, UserScope, old)
+
+	// Old files are read as they are, without being rewritten
+	cfg, err := LoadConfig(UserScope)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Version != CurrentVersion || cfg.Settings.MaxHistory != 20 {
+		t.Errorf("LoadConfig() = version %d, max_history %d", cfg.Version, cfg.Settings.MaxHistory)
+	}
+	if version, _ := FileVersion(UserScope); version != 0 {
+		t.Errorf("FileVersion() = %d before Migrate, want 0", version)
+	}
+
+	result, err := Migrate(UserScope)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if result.From != 0 || result.To != CurrentVersion || len(result.Applied) != CurrentVersion {
+		t.Errorf("Migrate() = %+v", result)
+	}
+	if backup, err := os.ReadFile(result.Backup); err != nil || string(backup) != old {
+		t.Errorf("backup %s = %q, %v, want the old file", result.Backup, backup, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"version": 1`) || !strings.Contains(string(data), `"team": "platform"`) {
+		t.Errorf("migrated file = %s", data)
+	}
+
+	result, err = Migrate(UserScope)
+	if err != nil || len(result.Applied) != 0 {
+		t.Errorf("second Migrate() = %+v, %v, want nothing applied", result, err)
+	}
+	if result, err := Migrate(ProjectScope); result != nil || err != nil {
+		t.Errorf("Migrate() without a file = %+v, %v, want nil", result, err)
+	}
+}
+
+func TestMigrate_BackupOnWrite(t *testing.T) {
+	setup(t)
+	path := writeConfig(t, ProjectScope, `{"settings": {"log_level": "warn"}}`)
+
+	if err := Set(ProjectScope, "settings.max_history", "10"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := os.Stat(backupPath(path, 0)); err != nil {
+		t.Errorf("no backup of the old file: %v", err)
+	}
+	if version, _ := FileVersion(ProjectScope); version != CurrentVersion {
+		t.Errorf("FileVersion() = %d after Set, want %d", version, CurrentVersion)
+	}
+
+	// Current files are not backed up again
+	os.Remove(backupPath(path, 0))
+	if err := Set(ProjectScope, "settings.max_history", "20"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := os.Stat(backupPath(path, 0)); err == nil {
+		t.Error("current file was backed up")
+	}
+}
+
+func TestMigrate_Steps(t *testing.T) {
+	registry := []Migration{
+		{From: 0, Description: "nothing", Migrate: func(map[string]interface{}) error { return nil }},
+		{From: 1, Description: "rename history", Migrate: func(raw map[string]interface{}) error {
+			settings, _ := raw["settings"].(map[string]interface{})
+			if v, ok := settings["history"]; ok {
+				settings["max_history"] = v
+				delete(settings, "history")
+			}
+			return nil
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		applied int
+		want    string
+		wantErr string
+	}{
+		{"unversioned", `{"settings": {"history": 5}}`, 2, `{"settings":{"max_history":5},"version":2}`, ""},
+		{"version 1", `{"version": 1, "settings": {"history": 5}}`, 1, `{"settings":{"max_history":5},"version":2}`, ""},
+		{"current", `{"version": 2, "settings": {"history": 5}}`, 0, `{"settings":{"history":5},"version":2}`, ""},
+		{"newer", `{"version": 3}`, 0, "", "newer than this build supports"},
+		{"invalid", `{"version": "one"}`, 0, "", "invalid config version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]interface{}
+			if err := decodeJSON([]byte(tt.file), &raw); err != nil {
+				t.Fatal(err)
+			}
+			applied, err := migrate(raw, registry)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("migrate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("migrate() error = %v", err)
+			}
+			got, _ := json.Marshal(raw)
+			if len(applied) != tt.applied || string(got) != tt.want {
+				t.Errorf("migrate() applied %d, raw = %s; want %d, %s", len(applied), got, tt.applied, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrate_NewerFile(t *testing.T) {
+	setup(t)
+	writeConfig(t, UserScope, `{"version": 99}`)
+
+	if _, err := LoadConfig(UserScope); err == nil || !strings.Contains(err.Error(), "newer than this build") {
+		t.Errorf("LoadConfig() error = %v, want newer version error", err)
+	}
+	if _, err := Migrate(UserScope); err == nil {
+		t.Error("Migrate() succeeded on a newer file")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"$schema", "version"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("schema has no %s property", name)
+		}
+	}
+	for _, key := range Keys() {
+		if _, ok := descriptions[key.Name]; !ok {
+			t.Errorf("key %s has no description", key.Name)
+		}
+	}
+
+	settings := properties["settings"].(map[string]interface{})
+	if settings["additionalProperties"] != false {
+		t.Error("settings allows unknown properties")
+	}
+	resolution := settings["properties"].(map[string]interface{})["agent_resolution"].(map[string]interface{})
+	if resolution["type"] != "array" || resolution["uniqueItems"] != true {
+		t.Errorf("agent_resolution schema = %v", resolution)
+	}
+	if prompts := properties["prompt_vars"].(map[string]interface{}); prompts["additionalProperties"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("prompt_vars schema = %v", prompts)
+	}
+}
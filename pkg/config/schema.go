@@ -0,0 +1,90 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// descriptions document the fields of config files in the JSON Schema,
+// keyed by their dotted path
+var descriptions = map[string]string{
+	"$schema":                       "JSON Schema that editors validate this file against",
+	"version":                       "Schema version of this file. Files with an older version are migrated with 'opencode-setup config migrate'.",
+	"default_agent":                 "Agent used when none is given",
+	"workflows":                     "Workflow aliases, mapping a name to a workflow",
+	"prompt_vars":                   "Variables available to agent content as {{.Vars.name}}",
+	"settings":                      "Application-wide settings",
+	"settings.log_level":            "Log level",
+	"settings.auto_save":            "Save sessions automatically",
+	"settings.show_hints":           "Show usage hints",
+	"settings.max_history":          "Maximum number of messages a conversation session keeps",
+	"settings.compaction_model":     "Model used to summarise older conversation turns",
+	"settings.compaction_threshold": "Estimated token count that triggers compaction (0 disables it)",
+	"settings.agent_resolution":     "Agent sources from highest to lowest precedence",
+}
+
+// constraints add what the Go types of config fields cannot express
+var constraints = map[string]map[string]interface{}{
+	"version":                       {"minimum": 0, "maximum": CurrentVersion},
+	"settings.max_history":          {"minimum": 0},
+	"settings.compaction_threshold": {"minimum": 0},
+	"settings.agent_resolution": {
+		"items":       map[string]interface{}{"type": "string", "enum": []string{string(ProjectScope), string(UserScope), SourceEmbedded}},
+		"minItems":    1,
+		"uniqueItems": true,
+	},
+}
+
+// JSONSchema returns a JSON Schema (draft-07) for config files, so editors
+// can validate config.json and complete its keys
+func JSONSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "opencode-setup configuration"
+	return schema
+}
+
+// typeSchema returns the schema of a config field's type. name is the
+// field's dotted path, empty for the top level.
+func typeSchema(t reflect.Type, name string) map[string]interface{} {
+	schema := make(map[string]interface{})
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldName := strings.Split(field.Tag.Get("json"), ",")[0]
+			if fieldName == "" || fieldName == "-" {
+				continue
+			}
+			if name != "" {
+				fieldName = name + "." + fieldName
+			}
+			property := typeSchema(field.Type, fieldName)
+			if desc, ok := descriptions[fieldName]; ok {
+				property["description"] = desc
+			}
+			properties[fieldName[strings.LastIndex(fieldName, ".")+1:]] = property
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = typeSchema(t.Elem(), "")
+	case reflect.Slice:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem(), "")
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int:
+		schema["type"] = "integer"
+	}
+
+	for k, v := range constraints[name] {
+		schema[k] = v
+	}
+	return schema
+}
@@ -0,0 +1,225 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of a line edit
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is one line of a line-based diff
+type Edit struct {
+	Op   Op
+	Line string // Line text including its newline, if any
+}
+
+// SplitLines splits text into lines, keeping each line's newline
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the edits that turn a into b, using a longest common
+// subsequence of lines
+func Lines(a, b []string) []Edit {
+	matches := lcs(a, b)
+
+	var edits []Edit
+	i, j := 0, 0
+	for _, m := range matches {
+		for ; i < m[0]; i++ {
+			edits = append(edits, Edit{Op: Delete, Line: a[i]})
+		}
+		for ; j < m[1]; j++ {
+			edits = append(edits, Edit{Op: Insert, Line: b[j]})
+		}
+		edits = append(edits, Edit{Op: Equal, Line: a[i]})
+		i++
+		j++
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, Edit{Op: Delete, Line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, Edit{Op: Insert, Line: b[j]})
+	}
+	return edits
+}
+
+// lcs returns index pairs of a longest common subsequence of a and b,
+// in increasing order. Common leading and trailing lines are matched
+// directly to keep the table small for typical edits.
+func lcs(a, b []string) [][2]int {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var matches [][2]int
+	for k := 0; k < prefix; k++ {
+		matches = append(matches, [2]int{k, k})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(ma), len(mb)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case ma[i] == mb[j]:
+			matches = append(matches, [2]int{prefix + i, prefix + j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		matches = append(matches, [2]int{len(a) - k, len(b) - k})
+	}
+	return matches
+}
+
+// Unified renders a unified diff between a and b with the given number of
+// context lines. It returns an empty string when the texts are equal.
+func Unified(aName, bName, a, b string, context int) string {
+	edits := Lines(SplitLines(a), SplitLines(b))
+
+	changed := false
+	for _, e := range edits {
+		if e.Op != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+
+	// Group edits into hunks separated by more than 2*context equal lines
+	for start := 0; start < len(edits); {
+		for start < len(edits) && edits[start].Op == Equal {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		end := start
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == Equal {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+		last := end + context
+		if last > len(edits) {
+			last = len(edits)
+		}
+
+		writeHunk(&out, edits, first, last)
+		start = last
+	}
+
+	return out.String()
+}
+
+// writeHunk writes edits[first:last] as a hunk with its line range header
+func writeHunk(out *strings.Builder, edits []Edit, first, last int) {
+	aStart, bStart := 1, 1
+	for _, e := range edits[:first] {
+		if e.Op != Insert {
+			aStart++
+		}
+		if e.Op != Delete {
+			bStart++
+		}
+	}
+
+	var body strings.Builder
+	aCount, bCount := 0, 0
+	for _, e := range edits[first:last] {
+		prefix := " "
+		switch e.Op {
+		case Delete:
+			prefix = "-"
+			aCount++
+		case Insert:
+			prefix = "+"
+			bCount++
+		default:
+			aCount++
+			bCount++
+		}
+		body.WriteString(prefix + e.Line)
+		if !strings.HasSuffix(e.Line, "\n") {
+			body.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	// An empty range starts at the line before it, as in GNU diff
+	if aCount == 0 {
+		aStart--
+	}
+	if bCount == 0 {
+		bStart--
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	out.WriteString(body.String())
+}
+
+// hunkRange formats a hunk line range, omitting a count of one
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
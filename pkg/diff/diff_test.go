@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo\n",
+			want: "",
+		},
+		{
+			name: "changed line",
+			a:    "one\ntwo\nthree\n",
+			b:    "one\n2\nthree\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\nnine\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -8,2 +8,2 @@\n 8\n-9\n+nine\n",
+		},
+		{
+			name: "insert into empty",
+			a:    "",
+			b:    "new\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n",
+		},
+		{
+			name: "missing final newline",
+			a:    "one\n",
+			b:    "one",
+			want: "--- a\n+++ b\n@@ -1 +1 @@\n-one\n+one\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a", "b", tt.a, tt.b, 1); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := "# Agent\n\n## Role\nWrites code.\n\n## Notes\n- one\n"
+
+	tests := []struct {
+		name          string
+		ours, theirs  string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "only theirs changed",
+			ours:   base,
+			theirs: "# Agent\n\n## Role\nWrites Go code.\n\n## Notes\n- one\n",
+			want:   "# Agent\n\n## Role\nWrites Go code.\n\n## Notes\n- one\n",
+		},
+		{
+			name:   "both changed different lines",
+			ours:   "# Agent\n\n## Role\nWrites code.\n\n## Notes\n- one\n- local\n",
+			theirs: "# Agent\n\n## Role\nWrites Go code.\n\n## Notes\n- one\n",
+			want:   "# Agent\n\n## Role\nWrites Go code.\n\n## Notes\n- one\n- local\n",
+		},
+		{
+			name:   "same change on both sides",
+			ours:   "# Agent v2\n\n## Role\nWrites code.\n\n## Notes\n- one\n",
+			theirs: "# Agent v2\n\n## Role\nWrites code.\n\n## Notes\n- one\n",
+			want:   "# Agent v2\n\n## Role\nWrites code.\n\n## Notes\n- one\n",
+		},
+		{
+			name:          "conflicting change",
+			ours:          "# Agent\n\n## Role\nWrites Rust code.\n\n## Notes\n- one\n",
+			theirs:        "# Agent\n\n## Role\nWrites Go code.\n\n## Notes\n- one\n",
+			want:          "# Agent\n\n## Role\n<<<<<<< local\nWrites Rust code.\n=======\nWrites Go code.\n>>>>>>> new\n\n## Notes\n- one\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(base, tt.ours, tt.theirs, "local", "new")
+			if got != tt.want || conflicts != tt.wantConflicts {
+				t.Errorf("Merge3() = %d conflicts\n%s\nwant %d conflicts\n%s", conflicts, got, tt.wantConflicts, tt.want)
+			}
+			if HasConflictMarkers(got) != (tt.wantConflicts > 0) {
+				t.Errorf("HasConflictMarkers() = %v, want %v", HasConflictMarkers(got), tt.wantConflicts > 0)
+			}
+		})
+	}
+}
+
+func TestLines_RoundTrip(t *testing.T) {
+	a := SplitLines("a\nb\nc\nd\n")
+	b := SplitLines("a\nc\nx\nd\ny\n")
+
+	var fromA, fromB strings.Builder
+	for _, e := range Lines(a, b) {
+		if e.Op != Insert {
+			fromA.WriteString(e.Line)
+		}
+		if e.Op != Delete {
+			fromB.WriteString(e.Line)
+		}
+	}
+	if fromA.String() != strings.Join(a, "") || fromB.String() != strings.Join(b, "") {
+		t.Errorf("Lines() edits do not reproduce inputs: %q / %q", fromA.String(), fromB.String())
+	}
+}
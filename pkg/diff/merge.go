@@ -0,0 +1,114 @@
+package diff
+
+import "strings"
+
+// Conflict marker lines written around unresolved changes
+const (
+	markerOurs   = "<<<<<<<"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// Merge3 merges the changes from base to ours and from base to theirs.
+// Regions changed differently on both sides are written with conflict
+// markers labelled oursLabel and theirsLabel. It returns the merged text and
+// the number of conflicts.
+func Merge3(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	baseLines := SplitLines(base)
+	oursLines := SplitLines(ours)
+	theirsLines := SplitLines(theirs)
+
+	inOurs := matchIndex(lcs(baseLines, oursLines), len(baseLines))
+	inTheirs := matchIndex(lcs(baseLines, theirsLines), len(baseLines))
+
+	var out strings.Builder
+	conflicts := 0
+	i, a, b := 0, 0, 0
+	for {
+		// Find the next base line kept unchanged on both sides
+		k := i
+		for k < len(baseLines) && (inOurs[k] < 0 || inTheirs[k] < 0) {
+			k++
+		}
+
+		endA, endB := len(oursLines), len(theirsLines)
+		if k < len(baseLines) {
+			endA, endB = inOurs[k], inTheirs[k]
+		}
+
+		baseChunk := baseLines[i:k]
+		oursChunk := oursLines[a:endA]
+		theirsChunk := theirsLines[b:endB]
+
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLines(&out, theirsChunk)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLines(&out, oursChunk)
+		default:
+			conflicts++
+			out.WriteString(markerOurs + " " + oursLabel + "\n")
+			writeLines(&out, oursChunk)
+			ensureNewline(&out)
+			out.WriteString(markerSep + "\n")
+			writeLines(&out, theirsChunk)
+			ensureNewline(&out)
+			out.WriteString(markerTheirs + " " + theirsLabel + "\n")
+		}
+
+		if k == len(baseLines) {
+			break
+		}
+		out.WriteString(baseLines[k])
+		i, a, b = k+1, endA+1, endB+1
+	}
+
+	return out.String(), conflicts
+}
+
+// HasConflictMarkers reports whether text contains unresolved conflict markers
+func HasConflictMarkers(text string) bool {
+	for _, line := range SplitLines(text) {
+		if strings.HasPrefix(line, markerOurs+" ") || strings.HasPrefix(line, markerTheirs+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchIndex maps each base line to its matched line on the other side, or -1
+func matchIndex(matches [][2]int, n int) []int {
+	index := make([]int, n)
+	for i := range index {
+		index[i] = -1
+	}
+	for _, m := range matches {
+		index[m[0]] = m[1]
+	}
+	return index
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// ensureNewline terminates the output's last line so a marker starts a new line
+func ensureNewline(out *strings.Builder) {
+	if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		out.WriteString("\n")
+	}
+}
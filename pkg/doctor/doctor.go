@@ -0,0 +1,363 @@
+// Package doctor diagnoses problems with an opencode-setup installation:
+// config directories, config files, installed agents and model providers
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/lint"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusWarning Status = "warning"
+	StatusError   Status = "error"
+	StatusSkipped Status = "skipped"
+)
+
+// Checks, in the order they are run
+const (
+	CheckDirectories = "directories"
+	CheckConfig      = "config"
+	CheckAgents      = "agents"
+	CheckShadowing   = "shadowing"
+	CheckEmbedded    = "embedded"
+	CheckCredentials = "credentials"
+	CheckEndpoints   = "endpoints"
+)
+
+// Result is one finding of a check
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // Suggested command or action
+}
+
+// Report holds the results of all checks
+type Report struct {
+	Results  []Result `json:"results"`
+	Errors   int      `json:"errors"`
+	Warnings int      `json:"warnings"`
+}
+
+// Options configure a doctor run
+type Options struct {
+	// Offline skips the provider endpoint checks
+	Offline bool
+	// Timeout bounds each endpoint check
+	Timeout time.Duration
+	// Probe checks that an endpoint answers. Nil uses an HTTP request.
+	Probe func(ctx context.Context, url string) error
+}
+
+// DefaultTimeout is the endpoint check timeout used when none is given
+const DefaultTimeout = 5 * time.Second
+
+var scopes = []config.Scope{config.UserScope, config.ProjectScope}
+
+// Run runs every check and returns the report
+func Run(ctx context.Context, opts Options) *Report {
+	r := &Report{}
+
+	for _, scope := range scopes {
+		checkDirectory(r, scope)
+	}
+	for _, scope := range scopes {
+		checkConfig(r, scope)
+	}
+	cfg := checkEffective(r)
+
+	installed := make(map[config.Scope][]config.AgentState)
+	for _, scope := range scopes {
+		installed[scope] = checkAgents(r, scope)
+	}
+	checkShadowing(r, installed, cfg.Settings.AgentResolution)
+	for _, scope := range scopes {
+		checkEmbedded(r, scope, installed[scope])
+	}
+
+	providers := checkCredentials(r, cfg)
+	checkEndpoints(ctx, r, providers, opts)
+
+	return r
+}
+
+// HasErrors reports whether any check failed
+func (r *Report) HasErrors() bool {
+	return r.Errors > 0
+}
+
+func (r *Report) add(check string, status Status, fix, format string, args ...interface{}) {
+	switch status {
+	case StatusError:
+		r.Errors++
+	case StatusWarning:
+		r.Warnings++
+	}
+	r.Results = append(r.Results, Result{Check: check, Status: status, Message: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+// checkDirectory checks that a scope's config directory exists and is writable
+func checkDirectory(r *Report, scope config.Scope) {
+	dir, err := config.GetConfigPath(scope)
+	if err != nil {
+		r.add(CheckDirectories, StatusError, "", "%s scope: %v", scope, err)
+		return
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fix := "opencode-setup init"
+		if scope == config.UserScope {
+			fix = "opencode-setup agents install --all --scope user"
+		}
+		r.add(CheckDirectories, StatusWarning, fix, "%s scope: %s does not exist", scope, dir)
+		return
+	case err != nil:
+		r.add(CheckDirectories, StatusError, "", "%s scope: %v", scope, err)
+		return
+	case !info.IsDir():
+		r.add(CheckDirectories, StatusError, fmt.Sprintf("move %s aside", dir), "%s scope: %s is not a directory", scope, dir)
+		return
+	}
+
+	for _, sub := range []string{dir, filepath.Join(dir, "agent")} {
+		if err := checkWritable(sub); err != nil {
+			r.add(CheckDirectories, StatusError, fmt.Sprintf("chmod u+w %s", sub), "%s scope: %s is not writable: %v", scope, sub, err)
+			return
+		}
+	}
+	r.add(CheckDirectories, StatusOK, "", "%s scope: %s is writable", scope, dir)
+}
+
+// checkWritable creates and removes a file in dir. A missing dir is not an
+// error; it is created when needed.
+func checkWritable(dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkConfig checks that a scope's config.json parses and is valid
+func checkConfig(r *Report, scope config.Scope) {
+	dir, err := config.GetConfigPath(scope)
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, "config.json")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		r.add(CheckConfig, StatusOK, "", "%s scope: no config.json, using defaults", scope)
+		return
+	}
+
+	cfg, err := config.LoadConfig(scope)
+	if err != nil {
+		r.add(CheckConfig, StatusError, fmt.Sprintf("fix the JSON in %s, or move it aside to use the defaults", path), "%s: %v", path, err)
+		return
+	}
+	if err := config.ValidateAgentResolution(cfg.Settings.AgentResolution); err != nil {
+		r.add(CheckConfig, StatusError, fmt.Sprintf("fix settings.agent_resolution in %s", path), "%s: %v", path, err)
+		return
+	}
+	if version, err := config.FileVersion(scope); err == nil && version < config.CurrentVersion {
+		r.add(CheckConfig, StatusWarning, fmt.Sprintf("opencode-setup config migrate --scope %s", scope),
+			"%s: schema version %d, current is %d", path, version, config.CurrentVersion)
+		return
+	}
+	r.add(CheckConfig, StatusOK, "", "%s parses", path)
+}
+
+// checkEffective loads the configuration the engine uses, merged from the
+// defaults, config files and OPENCODE_* environment variables. If it cannot
+// be loaded, the engine falls back to the defaults, and so does doctor;
+// problems not already reported for a config file come from the environment.
+func checkEffective(r *Report) *config.Config {
+	effective, err := config.LoadEffective()
+	if err == nil {
+		return effective.Config
+	}
+	if !hasError(r, CheckConfig) {
+		r.add(CheckConfig, StatusError, "fix or unset the "+config.EnvPrefix+"* environment variables", "environment: %v", err)
+	}
+	return config.DefaultConfig()
+}
+
+// hasError reports whether a check has reported an error
+func hasError(r *Report, check string) bool {
+	for _, result := range r.Results {
+		if result.Check == check && result.Status == StatusError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAgents lints the agents installed in a scope and reports agents whose
+// files were removed. It returns the installed agents.
+func checkAgents(r *Report, scope config.Scope) []config.AgentState {
+	states, err := config.GetInstalledAgents(scope)
+	if err != nil {
+		r.add(CheckAgents, StatusError, "", "%s scope: %v", scope, err)
+		return nil
+	}
+
+	clean := 0
+	for _, state := range states {
+		if state.Status == config.StatusMissing {
+			r.add(CheckAgents, StatusWarning,
+				fmt.Sprintf("opencode-setup agents install %s --scope %s, or opencode-setup agents uninstall %s --scope %s", state.Name, scope, state.Name, scope),
+				"%s: installed but the file was removed", state.Path)
+			continue
+		}
+
+		diags, err := lint.LintFile(state.Path)
+		if err != nil {
+			r.add(CheckAgents, StatusError, "", "%s: %v", state.Path, err)
+			continue
+		}
+
+		errs, warnings := 0, 0
+		for _, d := range diags {
+			if d.Severity == lint.SeverityError {
+				errs++
+			} else {
+				warnings++
+			}
+		}
+		fix := fmt.Sprintf("opencode-setup agents lint %s", state.Path)
+		switch {
+		case errs > 0:
+			r.add(CheckAgents, StatusError, fix, "%s: %d lint errors, %d warnings", state.Path, errs, warnings)
+		case warnings > 0:
+			r.add(CheckAgents, StatusWarning, fix, "%s: %d lint warnings", state.Path, warnings)
+		default:
+			clean++
+		}
+	}
+
+	if clean > 0 {
+		r.add(CheckAgents, StatusOK, "", "%s scope: %d agents lint cleanly", scope, clean)
+	} else if len(states) == 0 {
+		r.add(CheckAgents, StatusOK, "", "%s scope: no agents installed", scope)
+	}
+	return states
+}
+
+// checkShadowing reports agents defined in both scopes, where only the one
+// with higher precedence is used, and custom files that replace a built-in
+// agent. Override files patch the agent below them and are not reported.
+func checkShadowing(r *Report, installed map[config.Scope][]config.AgentState, order []string) {
+	found := false
+
+	byName := make(map[string]map[config.Scope]config.AgentState)
+	var names []string
+	for _, scope := range scopes {
+		for _, state := range installed[scope] {
+			if state.Status == config.StatusMissing {
+				continue
+			}
+			if byName[state.Name] == nil {
+				byName[state.Name] = make(map[config.Scope]config.AgentState)
+				names = append(names, state.Name)
+			}
+			byName[state.Name][scope] = state
+		}
+	}
+
+	for _, name := range names {
+		defined := byName[name]
+		_, err := resources.GetDefinition(name)
+		builtin := err == nil
+
+		// Walk the sources from highest precedence down to the first one
+		// that is not an override
+		var used *config.AgentState
+		for _, source := range order {
+			if source == config.SourceEmbedded {
+				break
+			}
+			state, ok := defined[config.Scope(source)]
+			if !ok {
+				continue
+			}
+			if used != nil {
+				found = true
+				r.add(CheckShadowing, StatusWarning,
+					fmt.Sprintf("opencode-setup agents uninstall %s --scope %s, or set override: true in %s to patch it instead", name, state.Scope, used.Path),
+					"%s shadows %s", used.Path, state.Path)
+				break
+			}
+			if isOverride(state.Path) {
+				continue
+			}
+			s := state
+			used = &s
+		}
+
+		if used != nil && builtin && used.Status == config.StatusUnmanaged {
+			found = true
+			r.add(CheckShadowing, StatusWarning,
+				fmt.Sprintf("rename %s, or set override: true in it to patch the built-in agent", used.Path),
+				"%s replaces the built-in %s agent", used.Path, name)
+		}
+	}
+
+	if !found {
+		r.add(CheckShadowing, StatusOK, "", "no agents are shadowed")
+	}
+}
+
+// isOverride reports whether an agent file patches the agent below it
+func isOverride(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	def, err := resources.ParseDefinition(filepath.Base(path), string(content))
+	return err == nil && def.Frontmatter.Override
+}
+
+// checkEmbedded checks that installed copies of built-in agents match the
+// definitions embedded in this binary
+func checkEmbedded(r *Report, scope config.Scope, states []config.AgentState) {
+	matching := 0
+	for _, state := range states {
+		if state.Source != config.SourceEmbedded {
+			continue
+		}
+		switch {
+		case state.Status == config.StatusModified:
+			r.add(CheckEmbedded, StatusWarning, fmt.Sprintf("opencode-setup agents diff %s --scope %s", state.Name, scope),
+				"%s: modified since it was installed", state.Path)
+		case state.Status == config.StatusMissing:
+			// Reported by the agents check
+		case installer.UpdateAvailable(state):
+			r.add(CheckEmbedded, StatusWarning, fmt.Sprintf("opencode-setup agents install %s --scope %s", state.Name, scope),
+				"%s: installed version %s differs from embedded %s", state.Path, state.Version, resources.BundleVersion())
+		default:
+			matching++
+		}
+	}
+	if matching > 0 {
+		r.add(CheckEmbedded, StatusOK, "", "%s scope: %d built-in agents match the embedded version", scope, matching)
+	}
+}
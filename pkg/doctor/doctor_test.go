@@ -0,0 +1,196 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+// setup gives the test an empty home and project directory, with no
+// provider credentials
+func setup(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func install(t *testing.T, scope config.Scope, names ...string) {
+	t.Helper()
+	var agents []resources.AgentResource
+	for _, name := range names {
+		agent, err := resources.GetAgent(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		agents = append(agents, agent)
+	}
+	if _, err := installer.InstallAgents(agents, scope, installer.InstallOptions{}); err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// find returns the results of a check with the given status
+func find(r *Report, check string, status Status) []Result {
+	var found []Result
+	for _, result := range r.Results {
+		if result.Check == check && result.Status == status {
+			found = append(found, result)
+		}
+	}
+	return found
+}
+
+func reachable(context.Context, string) error { return nil }
+
+func TestRun_Healthy(t *testing.T) {
+	setup(t)
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	install(t, config.UserScope, "tester", "reviewer")
+	install(t, config.ProjectScope, "debugger")
+
+	r := Run(context.Background(), Options{Probe: reachable})
+	if r.Errors != 0 || r.Warnings != 0 {
+		t.Fatalf("Run() = %d errors, %d warnings, want none: %+v", r.Errors, r.Warnings, r.Results)
+	}
+	if got := find(r, CheckEndpoints, StatusOK); len(got) != 1 || !strings.HasPrefix(got[0].Message, "anthropic:") {
+		t.Errorf("endpoint results = %+v, want anthropic reachable", got)
+	}
+}
+
+func TestRun_Problems(t *testing.T) {
+	setup(t)
+	install(t, config.UserScope, "tester", "reviewer")
+	install(t, config.ProjectScope, "tester")
+
+	userDir, _ := config.GetConfigPath(config.UserScope)
+	projectDir, _ := config.GetConfigPath(config.ProjectScope)
+	writeFile(t, filepath.Join(projectDir, "config.json"), `{"settings": `)
+	writeFile(t, filepath.Join(userDir, "agent", "reviewer.md"), "# Edited reviewer\n")
+	writeFile(t, filepath.Join(projectDir, "agent", "debugger.md"),
+		"---\ndescription: Local debugger\nmode: subagent\n---\n\n## Role\nDebugs.\n\n## Responsibilities\n- Debug\n\n## Output Deliverables\n- Fixes\n")
+
+	r := Run(context.Background(), Options{Probe: func(context.Context, string) error {
+		return errors.New("connection refused")
+	}})
+
+	tests := []struct {
+		check   string
+		status  Status
+		message string
+	}{
+		{CheckConfig, StatusError, "failed to parse config file"},
+		{CheckAgents, StatusError, "reviewer.md: 3 lint errors"},
+		{CheckShadowing, StatusWarning, filepath.Join(".opencode", "agent", "tester.md") + " shadows"},
+		{CheckShadowing, StatusWarning, "replaces the built-in debugger agent"},
+		{CheckEmbedded, StatusWarning, "reviewer.md: modified since it was installed"},
+		{CheckCredentials, StatusError, "anthropic: no API key"},
+		{CheckEndpoints, StatusWarning, "connection refused"},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, result := range find(r, tt.check, tt.status) {
+			if strings.Contains(result.Message, tt.message) {
+				found = true
+				if result.Fix == "" {
+					t.Errorf("%s result %q has no fix", tt.check, result.Message)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("no %s %s result containing %q in %+v", tt.check, tt.status, tt.message, r.Results)
+		}
+	}
+	if !r.HasErrors() {
+		t.Error("HasErrors() = false, want true")
+	}
+}
+
+func TestRun_OverrideNotShadowed(t *testing.T) {
+	setup(t)
+	install(t, config.UserScope, "tester")
+	projectDir, _ := config.GetConfigPath(config.ProjectScope)
+	writeFile(t, filepath.Join(projectDir, "agent", "tester.md"), "---\noverride: true\ntemperature: 0.1\n---\n")
+
+	r := Run(context.Background(), Options{Offline: true})
+	if got := find(r, CheckShadowing, StatusWarning); len(got) != 0 {
+		t.Errorf("shadowing warnings = %+v, want none for an override", got)
+	}
+	if got := find(r, CheckEndpoints, StatusSkipped); len(got) != 1 {
+		t.Errorf("endpoint results = %+v, want skipped when offline", r.Results)
+	}
+}
+
+func TestRun_EffectiveConfig(t *testing.T) {
+	setup(t)
+	install(t, config.UserScope, "tester")
+	install(t, config.ProjectScope, "tester")
+	projectDir, _ := config.GetConfigPath(config.ProjectScope)
+	writeFile(t, filepath.Join(projectDir, "config.json"), `{"version": 1, "settings": {"compaction_model": "openai/gpt-4o-mini"}}`)
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENCODE_SETTINGS_AGENT_RESOLUTION", "user,project,embedded")
+
+	r := Run(context.Background(), Options{Offline: true})
+	userTester := filepath.Join(".config", "opencode", "agent", "tester.md") + " shadows"
+	for _, want := range []struct {
+		check, message string
+	}{
+		{CheckShadowing, userTester},
+		{CheckCredentials, "openai: no API key"},
+	} {
+		found := false
+		for _, result := range r.Results {
+			found = found || (result.Check == want.check && strings.Contains(result.Message, want.message))
+		}
+		if !found {
+			t.Errorf("no %s result containing %q in %+v", want.check, want.message, r.Results)
+		}
+	}
+
+	t.Setenv("OPENCODE_SETTINGS_AGENT_RESOLUTION", "user,nowhere")
+	r = Run(context.Background(), Options{Offline: true})
+	if got := find(r, CheckConfig, StatusError); len(got) != 1 || !strings.Contains(got[0].Message, "environment") {
+		t.Errorf("config errors = %+v, want one for the environment", got)
+	}
+}
+
+func TestCheckCredentials_AuthFile(t *testing.T) {
+	setup(t)
+	writeFile(t, authPath(), `{"anthropic": {"type": "api", "key": "test-key"}}`)
+
+	r := &Report{}
+	known := checkCredentials(r, config.DefaultConfig())
+	if len(known) != 1 || known[0] != "anthropic" {
+		t.Errorf("checkCredentials() = %v, want [anthropic]", known)
+	}
+	if got := find(r, CheckCredentials, StatusOK); len(got) != 1 {
+		t.Errorf("credential results = %+v, want anthropic found in auth.json", r.Results)
+	}
+}
@@ -0,0 +1,216 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+)
+
+// provider describes how a model provider is authenticated and reached
+type provider struct {
+	envVars  []string // Any one of these holds the API key; none for local providers
+	endpoint string
+}
+
+// providers are the model providers doctor knows how to check, keyed by the
+// provider part of "provider/model"
+var providers = map[string]provider{
+	"anthropic":  {envVars: []string{"ANTHROPIC_API_KEY"}, endpoint: "https://api.anthropic.com"},
+	"openai":     {envVars: []string{"OPENAI_API_KEY"}, endpoint: "https://api.openai.com"},
+	"google":     {envVars: []string{"GEMINI_API_KEY", "GOOGLE_GENERATIVE_AI_API_KEY"}, endpoint: "https://generativelanguage.googleapis.com"},
+	"openrouter": {envVars: []string{"OPENROUTER_API_KEY"}, endpoint: "https://openrouter.ai/api"},
+	"groq":       {envVars: []string{"GROQ_API_KEY"}, endpoint: "https://api.groq.com"},
+	"mistral":    {envVars: []string{"MISTRAL_API_KEY"}, endpoint: "https://api.mistral.ai"},
+	"deepseek":   {envVars: []string{"DEEPSEEK_API_KEY"}, endpoint: "https://api.deepseek.com"},
+	"xai":        {envVars: []string{"XAI_API_KEY"}, endpoint: "https://api.x.ai"},
+	"ollama":     {endpoint: "http://localhost:11434"},
+}
+
+// usedProviders returns the providers of the models in use, each with what
+// uses it: the installer default model, the compaction model and the models
+// of the agents as the engine resolves them, with the resolution order,
+// inheritance and override files applied
+func usedProviders(cfg *config.Config) map[string][]string {
+	used := make(map[string][]string)
+	add := func(model, user string) {
+		name, _, ok := strings.Cut(model, "/")
+		if !ok || name == "" {
+			return
+		}
+		for _, u := range used[name] {
+			if u == user {
+				return
+			}
+		}
+		used[name] = append(used[name], user)
+	}
+
+	add(installer.DefaultModel, "default model")
+	add(cfg.Settings.CompactionModel, "compaction")
+
+	// Agents that fail to load are reported by the agents check
+	if agents, err := agent.NewEngine().ListAvailableAgents(); err == nil {
+		for _, a := range agents {
+			add(a.Model, a.Name)
+		}
+	}
+	return used
+}
+
+// checkCredentials checks that every provider in use has an API key, from
+// the environment or from OpenCode's auth.json. It returns the known
+// providers in use, sorted by name.
+func checkCredentials(r *Report, cfg *config.Config) []string {
+	used := usedProviders(cfg)
+	auth := loadAuth()
+
+	var names []string
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var known []string
+	for _, name := range names {
+		users := strings.Join(used[name], ", ")
+		p, ok := providers[name]
+		if !ok {
+			r.add(CheckCredentials, StatusWarning, "", "%s: unknown provider, credentials not checked (used by %s)", name, users)
+			continue
+		}
+		known = append(known, name)
+
+		if len(p.envVars) == 0 {
+			r.add(CheckCredentials, StatusOK, "", "%s: no credentials needed", name)
+			continue
+		}
+		if env := setEnvVar(p.envVars); env != "" {
+			r.add(CheckCredentials, StatusOK, "", "%s: %s is set", name, env)
+			continue
+		}
+		if auth[name] {
+			r.add(CheckCredentials, StatusOK, "", "%s: credentials found in %s", name, authPath())
+			continue
+		}
+		r.add(CheckCredentials, StatusError, fmt.Sprintf("export %s=<key>, or run: opencode auth login", p.envVars[0]),
+			"%s: no API key (used by %s)", name, users)
+	}
+	return known
+}
+
+// setEnvVar returns the first of vars that is set to a non-empty value
+func setEnvVar(vars []string) string {
+	for _, v := range vars {
+		if os.Getenv(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// authPath returns the file where "opencode auth login" stores credentials
+func authPath() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "opencode", "auth.json")
+}
+
+// loadAuth returns the providers that have credentials in auth.json
+func loadAuth() map[string]bool {
+	found := make(map[string]bool)
+
+	data, err := os.ReadFile(authPath())
+	if err != nil {
+		return found
+	}
+	var entries map[string]json.RawMessage
+	if json.Unmarshal(data, &entries) != nil {
+		return found
+	}
+	for name := range entries {
+		found[name] = true
+	}
+	return found
+}
+
+// checkEndpoints checks that the API endpoint of each provider answers
+// within the timeout. The checks run concurrently.
+func checkEndpoints(ctx context.Context, r *Report, names []string, opts Options) {
+	if opts.Offline {
+		r.add(CheckEndpoints, StatusSkipped, "", "endpoint checks skipped (offline)")
+		return
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	probe := opts.Probe
+	if probe == nil {
+		probe = httpProbe
+	}
+
+	type outcome struct {
+		err     error
+		elapsed time.Duration
+	}
+	outcomes := make([]outcome, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := probe(ctx, url)
+			outcomes[i] = outcome{err: err, elapsed: time.Since(start)}
+		}(i, providers[name].endpoint)
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		endpoint := providers[name].endpoint
+		if err := outcomes[i].err; err != nil {
+			r.add(CheckEndpoints, StatusWarning, "check your network connection, proxy settings (HTTPS_PROXY) and firewall",
+				"%s: %s is not reachable: %v", name, endpoint, err)
+			continue
+		}
+		r.add(CheckEndpoints, StatusOK, "", "%s: %s reachable in %dms", name, endpoint, outcomes[i].elapsed.Milliseconds())
+	}
+}
+
+// httpProbe sends a request to url. Any HTTP response, including an error
+// status, shows that the endpoint is reachable.
+func httpProbe(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,203 @@
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter opens and closes a frontmatter block
+const delimiter = "---"
+
+// Frontmatter holds the agent metadata stored in a markdown file header.
+// Keys without a dedicated field are kept in Extra so they survive a
+// parse and render round trip.
+type Frontmatter struct {
+	Description    string                 `yaml:"description,omitempty"`
+	Mode           string                 `yaml:"mode,omitempty"`
+	Model          string                 `yaml:"model,omitempty"`
+	Temperature    *float64               `yaml:"temperature,omitempty"`
+	ProjectContext []string               `yaml:"project_context,omitempty,flow"`
+	ContextBudget  int                    `yaml:"context_budget,omitempty"`
+	Tools          map[string]bool        `yaml:"tools"`
+	Extends        string                 `yaml:"extends,omitempty"`
+	Sections       map[string]string      `yaml:"sections,omitempty"`
+	Override       bool                   `yaml:"override,omitempty"`
+	Extra          map[string]interface{} `yaml:",inline"`
+}
+
+// Document is a markdown file split into frontmatter and body
+type Document struct {
+	Frontmatter    Frontmatter
+	Body           string
+	HasFrontmatter bool
+	BodyLine       int            // 1-based line number where the body starts
+	Lines          map[string]int // File line of each key, nested keys joined by "."
+}
+
+// Line returns the file line of a frontmatter key, or of the opening
+// delimiter when the key is absent
+func (d *Document) Line(key string) int {
+	if line, ok := d.Lines[key]; ok {
+		return line
+	}
+	return 1
+}
+
+// Error describes a frontmatter problem at a specific line of the file
+type Error struct {
+	Line int // 1-based line number in the file, 0 if unknown
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return e.Msg
+}
+
+// yamlLine matches the line references in yaml.v3 error messages
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// Parse splits content into frontmatter and body. Content that does not
+// start with a "---" line has no frontmatter and is returned as the body.
+func Parse(content string) (*Document, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.Split(content, "\n")
+
+	if strings.TrimRight(lines[0], "\r") != delimiter {
+		return &Document{Body: content, BodyLine: 1}, nil
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r") == delimiter {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, &Error{Line: 1, Msg: "frontmatter is not terminated by a closing '---' line"}
+	}
+
+	doc := &Document{HasFrontmatter: true, Lines: make(map[string]int)}
+
+	raw := strings.Join(lines[1:end], "\n")
+	if err := decode(raw, &doc.Frontmatter, doc.Lines); err != nil {
+		return nil, err
+	}
+
+	// The renderer separates frontmatter and body with one blank line
+	body := lines[end+1:]
+	doc.BodyLine = end + 2
+	if len(body) > 0 && strings.TrimSpace(body[0]) == "" {
+		body = body[1:]
+		doc.BodyLine++
+	}
+	doc.Body = strings.Join(body, "\n")
+
+	return doc, nil
+}
+
+// decode unmarshals raw YAML, recording key lines and translating error
+// positions to file lines
+func decode(raw string, fm *Frontmatter, lines map[string]int) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &root); err != nil {
+		return translateError(err)
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	recordLines(root.Content[0], "", lines)
+
+	if err := root.Content[0].Decode(fm); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// recordLines stores the file line of every mapping key under node
+func recordLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		lines[key] = node.Content[i].Line + 1
+		recordLines(node.Content[i+1], key+".", lines)
+	}
+}
+
+// translateError converts a yaml.v3 error into an Error with a file line.
+// The frontmatter starts on line 2, after the opening delimiter.
+func translateError(err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		return lineError(typeErr.Errors[0])
+	}
+	return lineError(err.Error())
+}
+
+// lineError builds an Error from a single yaml message
+func lineError(msg string) error {
+	m := yamlLine.FindStringSubmatch(msg)
+	if m == nil {
+		return &Error{Msg: strings.TrimPrefix(msg, "yaml: ")}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &Error{Line: line + 1, Msg: m[2]}
+}
+
+// Marshal renders frontmatter fields as YAML without delimiters
+func Marshal(fm Frontmatter) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(fm); err != nil {
+		return "", fmt.Errorf("failed to marshal frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to marshal frontmatter: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Render produces a markdown file with the frontmatter header and body
+func Render(fm Frontmatter, body string) (string, error) {
+	header, err := Marshal(fm)
+	if err != nil {
+		return "", err
+	}
+	return delimiter + "\n" + header + delimiter + "\n\n" + body, nil
+}
+
+// Keys returns the frontmatter keys that have a dedicated field
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Frontmatter{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// Float returns a pointer to v, for setting optional numeric fields
+func Float(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,107 @@
+package frontmatter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const sample = `---
+description: "Writes code: carefully"
+mode: primary
+model: anthropic/claude-sonnet-4-20250514
+temperature: 0.3
+tools:
+  bash: true
+  write: false
+permission:
+  edit: ask
+---
+
+# Implementer
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	fm := doc.Frontmatter
+	if fm.Description != "Writes code: carefully" || fm.Mode != "primary" || fm.Model != "anthropic/claude-sonnet-4-20250514" {
+		t.Errorf("Parse() scalar fields = %+v", fm)
+	}
+	if fm.Temperature == nil || *fm.Temperature != 0.3 {
+		t.Errorf("Parse() temperature = %v, want 0.3", fm.Temperature)
+	}
+	if !fm.Tools["bash"] || fm.Tools["write"] || len(fm.Tools) != 2 {
+		t.Errorf("Parse() tools = %v, want bash=true write=false", fm.Tools)
+	}
+	if _, ok := fm.Extra["permission"]; !ok {
+		t.Errorf("Parse() dropped unknown key, extra = %v", fm.Extra)
+	}
+	if doc.Body != "# Implementer\n" || doc.BodyLine != 13 {
+		t.Errorf("Parse() body = %q at line %d, want %q at line 13", doc.Body, doc.BodyLine, "# Implementer\n")
+	}
+}
+
+func TestRender_RoundTrip(t *testing.T) {
+	doc, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	rendered, err := Render(doc.Frontmatter, doc.Body)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(rendered, "  edit: ask\n") {
+		t.Errorf("Render() lost unknown key:\n%s", rendered)
+	}
+
+	again, err := Parse(rendered)
+	if err != nil {
+		t.Fatalf("Parse(Render()) error = %v", err)
+	}
+	if again.Body != doc.Body || again.Frontmatter.Description != doc.Frontmatter.Description ||
+		*again.Frontmatter.Temperature != *doc.Frontmatter.Temperature || len(again.Frontmatter.Tools) != 2 {
+		t.Errorf("round trip changed document:\n%s", rendered)
+	}
+}
+
+func TestParse_NoFrontmatter(t *testing.T) {
+	doc, err := Parse("# Agent\n\n---\nnot frontmatter\n---\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.HasFrontmatter || !strings.HasPrefix(doc.Body, "# Agent") {
+		t.Errorf("Parse() = %+v, want whole content as body", doc)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int
+	}{
+		{"unterminated", "---\nmode: primary\n", 1},
+		{"bad temperature", "---\nmode: primary\ntemperature: warm\n---\n", 3},
+		{"nested mapping value", "---\ndescription: x\nmode: a: b\n---\n", 3},
+		{"tab indentation", "---\ndescription: x\n\tmode: primary\n---\n", 3},
+		{"tools not a map", "---\nmode: primary\n\ntools: read\n---\n", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			var fmErr *Error
+			if !errors.As(err, &fmErr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if fmErr.Line != tt.wantLine {
+				t.Errorf("Parse() error line = %d (%v), want %d", fmErr.Line, err, tt.wantLine)
+			}
+		})
+	}
+}
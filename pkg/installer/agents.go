@@ -1,72 +1,382 @@
 package installer
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/diff"
+	"github.com/gsmlg-dev/open-code-agents/pkg/frontmatter"
 	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
 )
 
-// InstallAgent installs an agent to the specified scope
+// ConflictPolicy decides what happens to an installed agent file that has
+// local modifications when a new version is installed
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // Keep the local file
+	ConflictOverwrite ConflictPolicy = "overwrite" // Replace it with the new version
+	ConflictMerge     ConflictPolicy = "merge"     // Three-way merge local changes into the new version
+)
+
+// ParseConflictPolicy converts a policy name to a ConflictPolicy
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(name); policy {
+	case ConflictSkip, ConflictOverwrite, ConflictMerge:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q (must be %s, %s or %s)", name, ConflictSkip, ConflictOverwrite, ConflictMerge)
+	}
+}
+
+// Install actions reported in InstallResult
+const (
+	ActionInstalled   = "installed"   // New file written
+	ActionUpdated     = "updated"     // Unmodified file replaced with the new version
+	ActionUnchanged   = "unchanged"   // File already matches the new version
+	ActionSkipped     = "skipped"     // Modified file kept
+	ActionOverwritten = "overwritten" // Modified file replaced
+	ActionMerged      = "merged"      // Local changes merged into the new version
+)
+
+// InstallOptions controls how agents are installed over existing files
+type InstallOptions struct {
+	// OnConflict is asked how to handle an agent file with local
+	// modifications. If nil, modified files are overwritten.
+	OnConflict func(agent string) ConflictPolicy
+	// Progress, if set, is called before each agent is prepared
+	Progress func(current, total int, agent string)
+}
+
+// InstallResult describes what happened to one agent file
+type InstallResult struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Action    string `json:"action"`
+	Conflicts int    `json:"conflicts,omitempty"` // Unresolved merge conflicts left in the file
+}
+
+// InstallAgent installs an agent to the specified scope, overwriting any
+// existing file
 func InstallAgent(agent resources.AgentResource, scope config.Scope) error {
-	// Get agent directory for the scope
+	_, err := InstallAgentWithOptions(agent, scope, InstallOptions{})
+	return err
+}
+
+// InstallAgentWithOptions installs an agent to the specified scope. A file
+// changed since it was last installed is skipped, overwritten or merged as
+// decided by opts.OnConflict.
+func InstallAgentWithOptions(agent resources.AgentResource, scope config.Scope, opts InstallOptions) (*InstallResult, error) {
+	results, err := InstallAgents([]resources.AgentResource{agent}, scope, opts)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// plannedInstall is the outcome of installing one agent, before any file is written
+type plannedInstall struct {
+	result  *InstallResult
+	content string // File generated from the agent, recorded as the merge base
+	output  string // File to write, which differs from content after a merge
+}
+
+// planInstall decides what installing an agent would write, consulting
+// opts.OnConflict if the installed file has local modifications
+func planInstall(agent resources.AgentResource, agentDir string, lock *config.LockFile, scope config.Scope, opts InstallOptions) (*plannedInstall, error) {
+	// Generate OpenCode format agent file
+	content, err := generateOpenCodeAgentFile(agent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate agent file for %s: %w", agent.Name, err)
+	}
+
+	agentFile := filepath.Join(agentDir, agent.Name+".md")
+	plan := &plannedInstall{
+		result:  &InstallResult{Name: agent.Name, Path: agentFile, Action: ActionInstalled},
+		content: content,
+		output:  content,
+	}
+
+	existing, err := os.ReadFile(agentFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return plan, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read agent file: %w", err)
+	case string(existing) == content:
+		plan.result.Action = ActionUnchanged
+		return plan, nil
+	}
+
+	plan.result.Action = ActionUpdated
+
+	// Files without a lock entry were not installed by this tool and count as modified
+	entry, locked := lock.Agents[agent.Name]
+	if locked && config.HashContent(existing) == entry.Hash {
+		return plan, nil
+	}
+
+	policy := ConflictOverwrite
+	if opts.OnConflict != nil {
+		policy = opts.OnConflict(agent.Name)
+	}
+
+	switch policy {
+	case ConflictSkip:
+		plan.result.Action = ActionSkipped
+	case ConflictMerge:
+		base, err := readBase(agent.Name, scope)
+		if err != nil {
+			return nil, err
+		}
+		plan.output, plan.result.Conflicts = diff.Merge3(base, string(existing), content, "local changes", "new version")
+		plan.result.Action = ActionMerged
+	default:
+		plan.result.Action = ActionOverwritten
+	}
+
+	return plan, nil
+}
+
+// InstallAgents installs agents to a scope as a single transaction. New
+// files are staged first, files they replace are backed up, and everything
+// is renamed into place; if any step fails, the scope is left as it was.
+// The lock file and merge bases are updated in the same transaction. An
+// install that changes nothing writes nothing and makes no backup.
+func InstallAgents(agents []resources.AgentResource, scope config.Scope, opts InstallOptions) ([]*InstallResult, error) {
+	configPath, err := config.GetConfigPath(scope)
+	if err != nil {
+		return nil, err
+	}
+	agentDir, err := config.GetAgentDir(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent directory: %w", err)
+	}
+	baseDir, err := config.GetAgentBaseDir(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := config.LoadLockFile(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction{root: configPath}
+	results := make([]*InstallResult, 0, len(agents))
+	now := time.Now().UTC()
+
+	for i, agent := range agents {
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(agents), agent.Name)
+		}
+
+		plan, err := planInstall(agent, agentDir, lock, scope, opts)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, plan.result)
+
+		hash := config.HashContent([]byte(plan.content))
+		switch plan.result.Action {
+		case ActionSkipped:
+			continue
+		case ActionUnchanged:
+			// A matching file only needs recording if the lock does not know it
+			if entry, ok := lock.Agents[agent.Name]; ok && entry.Hash == hash {
+				continue
+			}
+		default:
+			tx.add(plan.result.Path, plan.output)
+		}
+
+		// Record what was installed so later changes to the file can be detected and merged
+		tx.add(filepath.Join(baseDir, agent.Name+".md"), plan.content)
+		lock.Agents[agent.Name] = config.LockEntry{
+			Source:    config.SourceEmbedded,
+			Version:   resources.BundleVersion(),
+			Hash:      hash,
+			Installed: now,
+		}
+	}
+
+	// Nothing changed, so there is nothing to back up or roll back
+	if len(tx.files) == 0 {
+		return results, nil
+	}
+
+	lockPath, err := config.GetLockFilePath(scope)
+	if err != nil {
+		return nil, err
+	}
+	lockData, err := config.EncodeLockFile(lock)
+	if err != nil {
+		return nil, err
+	}
+	tx.add(lockPath, string(lockData))
+
+	var names []string
+	for _, agent := range agents {
+		names = append(names, agent.Name)
+	}
+	if err := tx.commit(names); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// readBase returns the version of an agent that was last installed, or an
+// empty base if none was recorded
+func readBase(name string, scope config.Scope) (string, error) {
+	baseDir, err := config.GetAgentBaseDir(scope)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, name+".md"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read agent base: %w", err)
+	}
+	return string(data), nil
+}
+
+// Errors returned by UninstallAgent
+var (
+	ErrNotInstalled = errors.New("agent is not installed")
+	ErrNotManaged   = errors.New("agent was not installed by opencode-setup")
+	ErrModified     = errors.New("agent file has local modifications")
+)
+
+// UninstallAgent removes an installed agent from the specified scope. Only
+// agents recorded in the scope's lock file can be removed, and a file that
+// changed since it was installed is kept unless force is set.
+func UninstallAgent(name string, scope config.Scope, force bool) error {
+	lock, err := config.LoadLockFile(scope)
+	if err != nil {
+		return err
+	}
+
 	agentDir, err := config.GetAgentDir(scope)
 	if err != nil {
 		return fmt.Errorf("failed to get agent directory: %w", err)
 	}
 
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(agentDir, 0755); err != nil {
-		return fmt.Errorf("failed to create agent directory: %w", err)
+	agentFile := filepath.Join(agentDir, name+".md")
+	entry, locked := lock.Agents[name]
+
+	content, err := os.ReadFile(agentFile)
+	if errors.Is(err, os.ErrNotExist) {
+		if locked {
+			// The file was deleted by hand; forget it
+			delete(lock.Agents, name)
+			if err := config.SaveLockFile(lock, scope); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("%s: %w in %s scope", name, ErrNotInstalled, scope)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read agent file: %w", err)
 	}
 
-	// Generate OpenCode format agent file
-	content := generateOpenCodeAgentFile(agent)
+	if !locked {
+		// Agents installed before lock files existed are recognised by
+		// comparing them with the embedded definition
+		expected, err := expectedContent(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, ErrNotManaged)
+		}
+		entry.Hash = config.HashContent([]byte(expected))
+	}
 
-	// Write agent file
-	agentFile := filepath.Join(agentDir, agent.Name+".md")
-	if err := os.WriteFile(agentFile, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write agent file: %w", err)
+	if !force && config.HashContent(content) != entry.Hash {
+		return fmt.Errorf("%s: %w", agentFile, ErrModified)
+	}
+
+	if err := os.Remove(agentFile); err != nil {
+		return fmt.Errorf("failed to remove agent file: %w", err)
+	}
+
+	if locked {
+		delete(lock.Agents, name)
+		if err := config.SaveLockFile(lock, scope); err != nil {
+			return fmt.Errorf("failed to update lock file: %w", err)
+		}
+	}
+
+	if baseDir, err := config.GetAgentBaseDir(scope); err == nil {
+		os.Remove(filepath.Join(baseDir, name+".md"))
 	}
 
 	return nil
 }
 
-// generateOpenCodeAgentFile converts agent resource to OpenCode format
-func generateOpenCodeAgentFile(agent resources.AgentResource) string {
-	// Generate tools configuration
-	toolsConfig := generateToolsConfig(agent.Tools)
-
-	// Generate frontmatter
-	frontmatter := fmt.Sprintf(`---
-description: %s
-mode: %s
-model: anthropic/claude-sonnet-4-20250514
-temperature: %.1f
-%s
----
-
-`, agent.Description, agent.Mode, agent.Temperature, toolsConfig)
-
-	return frontmatter + agent.Content
+// UpdateAvailable reports whether the embedded definition of an installed
+// agent differs from the version that was installed
+func UpdateAvailable(state config.AgentState) bool {
+	if state.Source != config.SourceEmbedded || state.Hash == "" {
+		return false
+	}
+
+	expected, err := expectedContent(state.Name)
+	if err != nil {
+		return false
+	}
+	return config.HashContent([]byte(expected)) != state.Hash
 }
 
-// generateToolsConfig creates tools configuration string
-func generateToolsConfig(tools map[string]bool) string {
-	if len(tools) == 0 {
-		return "tools: {}"
+// DiffAgent returns a unified diff from the agent file installed in scope to
+// the file that installing the current embedded agent would write. The diff
+// is empty when the installed file is up to date.
+func DiffAgent(name string, scope config.Scope) (string, error) {
+	expected, err := expectedContent(name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, ErrNotManaged)
 	}
 
-	config := "tools:\n"
-	for tool, enabled := range tools {
-		status := "false"
-		if enabled {
-			status = "true"
-		}
-		config += fmt.Sprintf("  %s: %s\n", tool, status)
+	agentDir, err := config.GetAgentDir(scope)
+	if err != nil {
+		return "", fmt.Errorf("failed to get agent directory: %w", err)
+	}
+
+	agentFile := filepath.Join(agentDir, name+".md")
+	installed, err := os.ReadFile(agentFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w in %s scope", name, ErrNotInstalled, scope)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read agent file: %w", err)
+	}
+
+	return diff.Unified(agentFile, fmt.Sprintf("%s.md (embedded %s)", name, resources.BundleVersion()), string(installed), expected, 3), nil
+}
+
+// expectedContent returns the file InstallAgent would write for an embedded agent
+func expectedContent(name string) (string, error) {
+	agent, err := resources.GetAgent(name)
+	if err != nil {
+		return "", err
+	}
+	return generateOpenCodeAgentFile(agent)
+}
+
+// DefaultModel is the model written for agents that do not specify one
+const DefaultModel = "anthropic/claude-sonnet-4-20250514"
+
+// generateOpenCodeAgentFile converts agent resource to OpenCode format
+func generateOpenCodeAgentFile(agent resources.AgentResource) (string, error) {
+	fm := agent.Frontmatter()
+	if fm.Model == "" {
+		fm.Model = DefaultModel
 	}
 
-	return config
+	return frontmatter.Render(fm, agent.Content)
 }
@@ -0,0 +1,333 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+func TestGenerateOpenCodeAgentFile_RoundTrip(t *testing.T) {
+	agent := resources.AgentResource{
+		Name:           "custom",
+		Description:    "Custom agent: handles edge cases",
+		Content:        "# Custom Agent\n\n## Role\nDoes things.\n",
+		Mode:           "primary",
+		Model:          "openai/gpt-4o",
+		Temperature:    0.2,
+		Tools:          map[string]bool{"read": true, "write": false, "bash": true},
+		ProjectContext: []string{"docs", "git"},
+		ContextBudget:  1500,
+		Extra:          map[string]interface{}{"color": "blue"},
+	}
+
+	content, err := generateOpenCodeAgentFile(agent)
+	if err != nil {
+		t.Fatalf("generateOpenCodeAgentFile() error = %v", err)
+	}
+
+	parsed, err := resources.ParseAgent(agent.Name, content)
+	if err != nil {
+		t.Fatalf("ParseAgent() error = %v\n%s", err, content)
+	}
+
+	if !reflect.DeepEqual(parsed, agent) {
+		t.Errorf("round trip mismatch:\n got  %+v\n want %+v\nfile:\n%s", parsed, agent, content)
+	}
+}
+
+func TestGenerateOpenCodeAgentFile_DefaultModel(t *testing.T) {
+	agent, err := resources.GetAgent("implementer")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+
+	content, err := generateOpenCodeAgentFile(agent)
+	if err != nil {
+		t.Fatalf("generateOpenCodeAgentFile() error = %v", err)
+	}
+
+	parsed, err := resources.ParseAgent(agent.Name, content)
+	if err != nil {
+		t.Fatalf("ParseAgent() error = %v", err)
+	}
+	if parsed.Model != DefaultModel {
+		t.Errorf("model = %q, want %q", parsed.Model, DefaultModel)
+	}
+	if !reflect.DeepEqual(parsed.Tools, agent.Tools) {
+		t.Errorf("tools = %v, want %v", parsed.Tools, agent.Tools)
+	}
+}
+
+func TestUninstallAgent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := resources.GetAgent("tester")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	agentFile := filepath.Join(agentDir, "tester.md")
+
+	install := func(modify bool) {
+		t.Helper()
+		if err := InstallAgent(agent, config.UserScope); err != nil {
+			t.Fatalf("InstallAgent() error = %v", err)
+		}
+		if modify {
+			f, err := os.OpenFile(agentFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString("\n## Team Notes\nLocal addition.\n")
+			f.Close()
+		}
+	}
+
+	install(false)
+	if err := UninstallAgent("tester", config.UserScope, false); err != nil {
+		t.Fatalf("UninstallAgent() error = %v", err)
+	}
+	if _, err := os.Stat(agentFile); !os.IsNotExist(err) {
+		t.Errorf("agent file still exists after uninstall")
+	}
+
+	if err := UninstallAgent("tester", config.UserScope, false); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("UninstallAgent() of removed agent error = %v, want ErrNotInstalled", err)
+	}
+
+	install(true)
+	if err := UninstallAgent("tester", config.UserScope, false); !errors.Is(err, ErrModified) {
+		t.Errorf("UninstallAgent() of modified agent error = %v, want ErrModified", err)
+	}
+	if err := UninstallAgent("tester", config.UserScope, true); err != nil {
+		t.Errorf("UninstallAgent() with force error = %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(agentDir, "custom.md"), []byte("# Custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := UninstallAgent("custom", config.UserScope, true); !errors.Is(err, ErrNotManaged) {
+		t.Errorf("UninstallAgent() of custom agent error = %v, want ErrNotManaged", err)
+	}
+}
+
+func TestInstallAgent_LockFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, name := range []string{"tester", "reviewer", "debugger"} {
+		agent, err := resources.GetAgent(name)
+		if err != nil {
+			t.Fatalf("GetAgent() error = %v", err)
+		}
+		if err := InstallAgent(agent, config.UserScope); err != nil {
+			t.Fatalf("InstallAgent() error = %v", err)
+		}
+	}
+
+	lock, err := config.LoadLockFile(config.UserScope)
+	if err != nil {
+		t.Fatalf("LoadLockFile() error = %v", err)
+	}
+	entry, ok := lock.Agents["tester"]
+	if !ok || entry.Source != config.SourceEmbedded || entry.Version != resources.BundleVersion() || entry.Installed.IsZero() {
+		t.Errorf("lock entry = %+v, want embedded source, bundle version and install time", entry)
+	}
+
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "reviewer.md"), []byte("# Edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(agentDir, "debugger.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "custom.md"), []byte("# Custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := config.GetInstalledAgents(config.UserScope)
+	if err != nil {
+		t.Fatalf("GetInstalledAgents() error = %v", err)
+	}
+
+	want := map[string]string{
+		"custom":   config.StatusUnmanaged,
+		"debugger": config.StatusMissing,
+		"reviewer": config.StatusModified,
+		"tester":   config.StatusInstalled,
+	}
+	if len(states) != len(want) {
+		t.Fatalf("GetInstalledAgents() = %d agents, want %d", len(states), len(want))
+	}
+	for _, state := range states {
+		if state.Status != want[state.Name] {
+			t.Errorf("%s status = %s, want %s", state.Name, state.Status, want[state.Name])
+		}
+		if state.Name == "tester" && UpdateAvailable(state) {
+			t.Errorf("UpdateAvailable(tester) = true right after install")
+		}
+	}
+
+	if err := UninstallAgent("tester", config.UserScope, false); err != nil {
+		t.Fatalf("UninstallAgent() error = %v", err)
+	}
+	if lock, _ := config.LoadLockFile(config.UserScope); len(lock.Agents) != 2 {
+		t.Errorf("lock file has %d agents after uninstall, want 2", len(lock.Agents))
+	}
+}
+
+func TestInstallAgentWithOptions_Conflicts(t *testing.T) {
+	agent := resources.AgentResource{
+		Name:        "custom",
+		Description: "Custom agent",
+		Content:     "# Custom\n\n## Role\nWrites code.\n\n## Notes\n- one\n",
+		Mode:        "subagent",
+		Temperature: 0.3,
+		Tools:       map[string]bool{"read": true},
+	}
+	upgraded := agent
+	upgraded.Content = "# Custom\n\n## Role\nWrites Go code.\n\n## Notes\n- one\n"
+
+	tests := []struct {
+		name          string
+		policy        ConflictPolicy
+		local         func(string) string
+		wantAction    string
+		wantConflicts int
+		wantContains  []string
+	}{
+		{
+			name:       "unmodified file is updated",
+			policy:     ConflictSkip,
+			local:      func(s string) string { return s },
+			wantAction: ActionUpdated,
+		},
+		{
+			name:         "skip keeps local file",
+			policy:       ConflictSkip,
+			local:        func(s string) string { return s + "- local\n" },
+			wantAction:   ActionSkipped,
+			wantContains: []string{"Writes code.", "- local"},
+		},
+		{
+			name:         "overwrite replaces local file",
+			policy:       ConflictOverwrite,
+			local:        func(s string) string { return s + "- local\n" },
+			wantAction:   ActionOverwritten,
+			wantContains: []string{"Writes Go code."},
+		},
+		{
+			name:         "merge keeps both changes",
+			policy:       ConflictMerge,
+			local:        func(s string) string { return s + "- local\n" },
+			wantAction:   ActionMerged,
+			wantContains: []string{"Writes Go code.", "- local"},
+		},
+		{
+			name:          "merge conflict leaves markers",
+			policy:        ConflictMerge,
+			local:         func(s string) string { return strings.Replace(s, "Writes code.", "Writes Rust code.", 1) },
+			wantAction:    ActionMerged,
+			wantConflicts: 1,
+			wantContains:  []string{"<<<<<<< local changes\nWrites Rust code.\n=======\nWrites Go code.\n>>>>>>> new version\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+
+			first, err := InstallAgentWithOptions(agent, config.UserScope, InstallOptions{})
+			if err != nil || first.Action != ActionInstalled {
+				t.Fatalf("first install = %+v, %v", first, err)
+			}
+			installed, err := os.ReadFile(first.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(first.Path, []byte(tt.local(string(installed))), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			asked := false
+			result, err := InstallAgentWithOptions(upgraded, config.UserScope, InstallOptions{
+				OnConflict: func(string) ConflictPolicy {
+					asked = true
+					return tt.policy
+				},
+			})
+			if err != nil {
+				t.Fatalf("InstallAgentWithOptions() error = %v", err)
+			}
+			if result.Action != tt.wantAction || result.Conflicts != tt.wantConflicts {
+				t.Errorf("result = %s with %d conflicts, want %s with %d", result.Action, result.Conflicts, tt.wantAction, tt.wantConflicts)
+			}
+			if asked != (tt.wantAction != ActionUpdated) {
+				t.Errorf("OnConflict called = %v", asked)
+			}
+
+			content, err := os.ReadFile(result.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("installed file missing %q:\n%s", want, content)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffAgent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	agent, err := resources.GetAgent("tester")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if err := InstallAgent(agent, config.UserScope); err != nil {
+		t.Fatalf("InstallAgent() error = %v", err)
+	}
+
+	if d, err := DiffAgent("tester", config.UserScope); err != nil || d != "" {
+		t.Errorf("DiffAgent() of fresh install = %q, %v, want empty diff", d, err)
+	}
+
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentFile := filepath.Join(agentDir, "tester.md")
+	content, err := os.ReadFile(agentFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(content), "temperature: 0.2", "temperature: 0.5", 1)
+	if err := os.WriteFile(agentFile, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := DiffAgent("tester", config.UserScope)
+	if err != nil {
+		t.Fatalf("DiffAgent() error = %v", err)
+	}
+	if !strings.Contains(d, "\n-temperature: 0.5\n+temperature: 0.2\n") || !strings.HasPrefix(d, "--- "+agentFile+"\n") {
+		t.Errorf("DiffAgent() =\n%s\nwant temperature change from the installed file", d)
+	}
+
+	if _, err := DiffAgent("tester", config.ProjectScope); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("DiffAgent() in empty scope error = %v, want ErrNotInstalled", err)
+	}
+}
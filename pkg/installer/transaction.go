@@ -0,0 +1,367 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+)
+
+// backupPrefix names backup directories, as setup-to-user.sh does
+const backupPrefix = "backup-"
+
+// backupManifest is the file in a backup directory describing the install
+const backupManifest = "backup.json"
+
+// maxBackups is how many backups are kept per scope
+const maxBackups = 10
+
+// ErrNoBackup is returned by Rollback when a scope has no backups
+var ErrNoBackup = errors.New("no backup to restore")
+
+// Backup records the files an install replaced or added, so that it can be
+// rolled back
+type Backup struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Agents   []string  `json:"agents"`   // Agents in the install
+	Replaced []string  `json:"replaced"` // Files overwritten, relative to the scope directory; copies are kept in the backup
+	Added    []string  `json:"added"`    // Files that did not exist before, relative to the scope directory
+	// Written maps the files the install wrote to the hash of their content,
+	// so that rollback can tell whether they were edited afterwards
+	Written map[string]string `json:"written,omitempty"`
+	Dir     string            `json:"-"`
+}
+
+// stagedFile is a file to be written by a transaction
+type stagedFile struct {
+	path    string
+	content string
+}
+
+// transaction writes a set of files under root all or nothing
+type transaction struct {
+	root  string
+	files []stagedFile
+}
+
+// add schedules a file to be written when the transaction commits
+func (tx *transaction) add(path, content string) {
+	tx.files = append(tx.files, stagedFile{path: path, content: content})
+}
+
+// commit stages every file in a temporary directory, backs up the files
+// they replace and renames them into place, restoring the backup if any
+// rename fails
+func (tx *transaction) commit(agents []string) error {
+	if err := os.MkdirAll(tx.root, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Staging inside the scope directory keeps renames on one filesystem
+	staging, err := os.MkdirTemp(tx.root, ".staging-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	staged := make([]string, len(tx.files))
+	for i, f := range tx.files {
+		staged[i] = filepath.Join(staging, fmt.Sprintf("%d", i))
+		if err := os.WriteFile(staged[i], []byte(f.content), 0644); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", f.path, err)
+		}
+	}
+
+	backup, err := tx.backup(agents)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range tx.files {
+		err := os.MkdirAll(filepath.Dir(f.path), 0755)
+		if err == nil {
+			err = os.Rename(staged[i], f.path)
+		}
+		if err != nil {
+			if restoreErr := restore(tx.root, backup); restoreErr != nil {
+				return fmt.Errorf("failed to install %s: %v; rollback failed: %w", f.path, err, restoreErr)
+			}
+			os.RemoveAll(backup.Dir)
+			return fmt.Errorf("failed to install %s, changes rolled back: %w", f.path, err)
+		}
+	}
+
+	return pruneBackups(tx.root)
+}
+
+// backup copies the files the transaction will replace into a new backup
+// directory and records the files it will add
+func (tx *transaction) backup(agents []string) (*Backup, error) {
+	now := time.Now()
+	backup := &Backup{Time: now.UTC(), Agents: agents, Written: make(map[string]string)}
+
+	// Installs within the same second get a numbered suffix
+	id := now.Format("20060102-150405")
+	for n := 2; ; n++ {
+		backup.ID = id
+		backup.Dir = filepath.Join(tx.root, backupPrefix+backup.ID)
+		err := os.Mkdir(backup.Dir, 0755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), n)
+	}
+
+	for _, f := range tx.files {
+		rel, err := filepath.Rel(tx.root, f.path)
+		if err != nil {
+			return nil, err
+		}
+		backup.Written[filepath.ToSlash(rel)] = config.HashContent([]byte(f.content))
+
+		data, err := os.ReadFile(f.path)
+		if errors.Is(err, os.ErrNotExist) {
+			backup.Added = append(backup.Added, filepath.ToSlash(rel))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", f.path, err)
+		}
+
+		if err := writeFile(filepath.Join(backup.Dir, rel), data); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", f.path, err)
+		}
+		backup.Replaced = append(backup.Replaced, filepath.ToSlash(rel))
+	}
+
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal backup manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(backup.Dir, backupManifest), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+
+	return backup, nil
+}
+
+// ListBackups returns the install backups of a scope, newest first
+func ListBackups(scope config.Scope) ([]*Backup, error) {
+	configPath, err := config.GetConfigPath(scope)
+	if err != nil {
+		return nil, err
+	}
+	return listBackups(configPath)
+}
+
+func listBackups(root string) ([]*Backup, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+
+	var backups []*Backup
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), backupPrefix) {
+			continue
+		}
+
+		dir := filepath.Join(root, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, backupManifest))
+		if err != nil {
+			// Backups made by setup-to-user.sh have no manifest
+			continue
+		}
+
+		var backup Backup
+		if err := json.Unmarshal(data, &backup); err != nil {
+			return nil, fmt.Errorf("failed to parse backup manifest in %s: %w", dir, err)
+		}
+		backup.Dir = dir
+		backups = append(backups, &backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// Rollback undoes an install by restoring the files it replaced and removing
+// the files it added. An empty id restores the most recent backup. The
+// backup is deleted once it has been restored. Agent files edited since the
+// install would be lost, so Rollback fails with ErrModified, changing
+// nothing, unless force is set.
+func Rollback(scope config.Scope, id string, force bool) (*Backup, error) {
+	configPath, err := config.GetConfigPath(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := listBackups(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("%w in %s scope", ErrNoBackup, scope)
+	}
+
+	backup := backups[0]
+	if id != "" {
+		backup = nil
+		for _, b := range backups {
+			if b.ID == id {
+				backup = b
+				break
+			}
+		}
+		if backup == nil {
+			return nil, fmt.Errorf("backup %s not found in %s scope", id, scope)
+		}
+	}
+
+	if !force {
+		modified, err := modifiedSince(configPath, backup, scope)
+		if err != nil {
+			return nil, err
+		}
+		if len(modified) > 0 {
+			return nil, fmt.Errorf("%w since install %s: %s", ErrModified, backup.ID, strings.Join(modified, ", "))
+		}
+	}
+
+	if err := restore(configPath, backup); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(backup.Dir); err != nil {
+		return nil, fmt.Errorf("failed to remove restored backup: %w", err)
+	}
+
+	return backup, nil
+}
+
+// modifiedSince returns the agent files an install wrote that have been
+// edited since. Backups made before their manifests recorded hashes are
+// checked against the lock entries of the agents.
+func modifiedSince(root string, backup *Backup, scope config.Scope) ([]string, error) {
+	agentDir, err := config.GetAgentDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	agentRel, err := filepath.Rel(root, agentDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock *config.LockFile
+	var modified []string
+	for _, rel := range append(append([]string{}, backup.Replaced...), backup.Added...) {
+		if !strings.HasPrefix(rel, filepath.ToSlash(agentRel)+"/") {
+			continue // Lock file and merge bases are not edited by hand
+		}
+
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+
+		hash, ok := backup.Written[rel]
+		if !ok {
+			if lock == nil {
+				if lock, err = config.LoadLockFile(scope); err != nil {
+					return nil, err
+				}
+			}
+			name := strings.TrimSuffix(path.Base(rel), ".md")
+			entry, locked := lock.Agents[name]
+			if !locked {
+				continue
+			}
+			hash = entry.Hash
+		}
+		if config.HashContent(content) != hash {
+			modified = append(modified, rel)
+		}
+	}
+	return modified, nil
+}
+
+// restore puts the files recorded in a backup back in place
+func restore(root string, backup *Backup) error {
+	for _, rel := range backup.Replaced {
+		data, err := os.ReadFile(filepath.Join(backup.Dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("failed to read backup of %s: %w", rel, err)
+		}
+		if err := writeFile(filepath.Join(root, filepath.FromSlash(rel)), data); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", rel, err)
+		}
+	}
+
+	for _, rel := range backup.Added {
+		err := os.Remove(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", rel, err)
+		}
+	}
+
+	return nil
+}
+
+// pruneBackups removes all but the newest maxBackups backups
+func pruneBackups(root string) error {
+	backups, err := listBackups(root)
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups[min(len(backups), maxBackups):] {
+		if err := os.RemoveAll(backup.Dir); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes data to path through a temporary file and a rename, so
+// readers never see a partially written file
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,206 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+func getAgents(t *testing.T, names ...string) []resources.AgentResource {
+	t.Helper()
+	var agents []resources.AgentResource
+	for _, name := range names {
+		agent, err := resources.GetAgent(name)
+		if err != nil {
+			t.Fatalf("GetAgent(%s) error = %v", name, err)
+		}
+		agents = append(agents, agent)
+	}
+	return agents
+}
+
+func TestInstallAgents_Rollback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testerFile := filepath.Join(agentDir, "tester.md")
+
+	if _, err := InstallAgents(getAgents(t, "tester"), config.UserScope, InstallOptions{}); err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+	edited := []byte("# Edited tester\n")
+	if err := os.WriteFile(testerFile, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := InstallAgents(getAgents(t, "tester", "reviewer"), config.UserScope, InstallOptions{})
+	if err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+	if results[0].Action != ActionOverwritten || results[1].Action != ActionInstalled {
+		t.Errorf("actions = %s, %s, want overwritten, installed", results[0].Action, results[1].Action)
+	}
+
+	backups, err := ListBackups(config.UserScope)
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("ListBackups() = %d backups, %v, want 2", len(backups), err)
+	}
+
+	backup, err := Rollback(config.UserScope, "", false)
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if backup.ID != backups[0].ID {
+		t.Errorf("Rollback() restored %s, want latest %s", backup.ID, backups[0].ID)
+	}
+
+	if content, err := os.ReadFile(testerFile); err != nil || string(content) != string(edited) {
+		t.Errorf("tester after rollback = %q, %v, want edited file restored", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(agentDir, "reviewer.md")); !os.IsNotExist(err) {
+		t.Errorf("reviewer still installed after rollback")
+	}
+	lock, err := config.LoadLockFile(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lock.Agents["reviewer"]; ok || len(lock.Agents) != 1 {
+		t.Errorf("lock file after rollback = %v, want only tester", lock.Agents)
+	}
+
+	// The restored tester holds edits made after the first install, so
+	// rolling that install back needs force to remove everything it added
+	if _, err := Rollback(config.UserScope, "", false); !errors.Is(err, ErrModified) {
+		t.Fatalf("Rollback() error = %v, want ErrModified", err)
+	}
+	if _, err := Rollback(config.UserScope, "", true); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if _, err := Rollback(config.UserScope, "", false); !errors.Is(err, ErrNoBackup) {
+		t.Errorf("Rollback() with no backups error = %v, want ErrNoBackup", err)
+	}
+}
+
+func TestRollback_EditedAfterInstall(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testerFile := filepath.Join(agentDir, "tester.md")
+
+	if _, err := InstallAgents(getAgents(t, "tester", "reviewer"), config.UserScope, InstallOptions{}); err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+	edited := []byte("# Edited tester\n")
+	if err := os.WriteFile(testerFile, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Rollback(config.UserScope, "", false)
+	if !errors.Is(err, ErrModified) || !strings.Contains(err.Error(), "agent/tester.md") {
+		t.Fatalf("Rollback() error = %v, want ErrModified naming tester.md", err)
+	}
+	if content, _ := os.ReadFile(testerFile); string(content) != string(edited) {
+		t.Errorf("tester after refused rollback = %q, want the edit kept", content)
+	}
+	if _, err := os.Stat(filepath.Join(agentDir, "reviewer.md")); err != nil {
+		t.Errorf("refused rollback removed reviewer: %v", err)
+	}
+	if backups, _ := ListBackups(config.UserScope); len(backups) != 1 {
+		t.Errorf("refused rollback left %d backups, want 1", len(backups))
+	}
+
+	if _, err := Rollback(config.UserScope, "", true); err != nil {
+		t.Fatalf("Rollback(force) error = %v", err)
+	}
+	if _, err := os.Stat(testerFile); !os.IsNotExist(err) {
+		t.Errorf("tester still installed after forced rollback")
+	}
+}
+
+func TestInstallAgents_NoChangeNoBackup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InstallAgents(getAgents(t, "tester", "reviewer"), config.UserScope, InstallOptions{}); err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+	before, err := config.LoadLockFile(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "reviewer.md"), []byte("# Edited reviewer\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// tester is unchanged and the edited reviewer is skipped
+	skip := InstallOptions{OnConflict: func(string) ConflictPolicy { return ConflictSkip }}
+	results, err := InstallAgents(getAgents(t, "tester", "reviewer"), config.UserScope, skip)
+	if err != nil {
+		t.Fatalf("InstallAgents() error = %v", err)
+	}
+	if results[0].Action != ActionUnchanged || results[1].Action != ActionSkipped {
+		t.Errorf("actions = %s, %s, want unchanged, skipped", results[0].Action, results[1].Action)
+	}
+
+	if backups, _ := ListBackups(config.UserScope); len(backups) != 1 {
+		t.Errorf("no-op install left %d backups, want 1", len(backups))
+	}
+	after, err := config.LoadLockFile(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !after.Agents["tester"].Installed.Equal(before.Agents["tester"].Installed) {
+		t.Errorf("no-op install changed the install time of tester")
+	}
+}
+
+func TestInstallAgents_FailureRollsBack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agentDir, err := config.GetAgentDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseDir, err := config.GetAgentBaseDir(config.UserScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	original := []byte("# Hand-written tester\n")
+	if err := os.WriteFile(filepath.Join(agentDir, "tester.md"), original, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A file where the merge base directory belongs makes the commit fail
+	// after the agent files have been renamed into place
+	if err := os.WriteFile(baseDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InstallAgents(getAgents(t, "tester", "reviewer"), config.UserScope, InstallOptions{}); err == nil {
+		t.Fatalf("InstallAgents() error = nil, want failure")
+	}
+
+	if content, err := os.ReadFile(filepath.Join(agentDir, "tester.md")); err != nil || string(content) != string(original) {
+		t.Errorf("tester after failed install = %q, %v, want original", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(agentDir, "reviewer.md")); !os.IsNotExist(err) {
+		t.Errorf("reviewer installed despite failed transaction")
+	}
+	if backups, _ := ListBackups(config.UserScope); len(backups) != 0 {
+		t.Errorf("failed install left %d backups", len(backups))
+	}
+}
@@ -7,13 +7,17 @@ import (
 	"strings"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
 	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
 )
 
-// readInput reads a line from stdin with proper buffering
-func readInput() string {
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
+// Stdin is shared by every menu and prompt that reads stdin, so that input
+// buffered by one read is not lost to the next
+var Stdin = bufio.NewReader(os.Stdin)
+
+// ReadInput reads a line from Stdin without surrounding whitespace
+func ReadInput() string {
+	input, _ := Stdin.ReadString('\n')
 	return strings.TrimSpace(input)
 }
 
@@ -23,10 +27,11 @@ func ShowAgentMenu() string {
 	fmt.Println("1. install all")
 	fmt.Println("2. select what to install")
 	fmt.Println("3. view current agents")
+	fmt.Println("4. uninstall agents")
 	fmt.Println("Q. Quit")
 	fmt.Print("\nSelect an option: ")
 
-	return readInput()
+	return ReadInput()
 }
 
 // ShowAgentSelection displays interactive agent selection menu
@@ -46,7 +51,7 @@ func ShowAgentSelection(agents []resources.AgentResource) []string {
 
 	fmt.Print("\nEnter agent numbers (comma-separated) or choice: ")
 
-	input := readInput()
+	input := ReadInput()
 
 	switch strings.ToUpper(input) {
 	case "A":
@@ -79,6 +84,38 @@ func ShowAgentSelection(agents []resources.AgentResource) []string {
 	}
 }
 
+// ShowUninstallSelection lets the user pick installed agents to remove
+func ShowUninstallSelection(agents []config.AgentState) []string {
+	fmt.Println("\n=== Select Agents to Uninstall ===")
+
+	for i, agent := range agents {
+		fmt.Printf("%d. %s (%s)\n", i+1, agent.Name, agent.Path)
+	}
+	fmt.Println("A. Select All")
+	fmt.Println("B. Back to main menu")
+
+	fmt.Print("\nEnter agent numbers (comma-separated) or choice: ")
+
+	input := ReadInput()
+
+	var selectedNames []string
+	switch strings.ToUpper(input) {
+	case "A":
+		for _, agent := range agents {
+			selectedNames = append(selectedNames, agent.Name)
+		}
+	case "B", "":
+	default:
+		for _, part := range strings.Split(input, ",") {
+			index := parseIndex(strings.TrimSpace(part))
+			if index >= 1 && index <= len(agents) {
+				selectedNames = append(selectedNames, agents[index-1].Name)
+			}
+		}
+	}
+	return selectedNames
+}
+
 // parseIndex converts string to index number
 func parseIndex(s string) int {
 	var num int
@@ -124,6 +161,48 @@ func ConfirmAgentInstallation(agents []resources.AgentResource, scope config.Sco
 	return response == "y" || response == "yes"
 }
 
+// ConfirmAgentRemoval shows the agents to uninstall and asks for confirmation
+func ConfirmAgentRemoval(names []string, scope config.Scope) bool {
+	fmt.Printf("\n=== Uninstall Summary ===\n")
+	fmt.Printf("Scope: %s\n", scope)
+	fmt.Printf("Agents to remove: %d\n", len(names))
+	for _, name := range names {
+		fmt.Printf("  • %s\n", name)
+	}
+	fmt.Print("\nContinue? (y/N): ")
+
+	return confirm()
+}
+
+// ConfirmForceRemoval asks whether to remove an agent file with local modifications
+func ConfirmForceRemoval(name string) bool {
+	fmt.Printf("\n%s has been modified since it was installed. Remove it anyway? (y/N): ", name)
+	return confirm()
+}
+
+// PromptConflictPolicy asks what to do with an agent file that was changed
+// since it was installed. The local file is kept by default.
+func PromptConflictPolicy(name string) installer.ConflictPolicy {
+	for {
+		fmt.Printf("%s has local modifications. [S]kip, [o]verwrite or [m]erge? ", name)
+
+		switch strings.ToLower(ReadInput()) {
+		case "", "s", "skip":
+			return installer.ConflictSkip
+		case "o", "overwrite":
+			return installer.ConflictOverwrite
+		case "m", "merge":
+			return installer.ConflictMerge
+		}
+	}
+}
+
+// confirm reads a yes/no answer, defaulting to no
+func confirm() bool {
+	response := strings.ToLower(ReadInput())
+	return response == "y" || response == "yes"
+}
+
 // ShowProgress displays installation progress
 func ShowProgress(current, total int, description string) {
 	percent := float64(current) / float64(total) * 100
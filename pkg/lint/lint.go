@@ -0,0 +1,293 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/frontmatter"
+	"github.com/gsmlg-dev/open-code-agents/pkg/project"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+	"github.com/gsmlg-dev/open-code-agents/pkg/tmpl"
+)
+
+// Severity indicates how serious a diagnostic is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single problem found in an agent file
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic as file:line: severity: message [rule]
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s]", d.File, d.Line, d.Severity, d.Message, d.Rule)
+}
+
+// RequiredSections are the markdown sections every agent must define
+var RequiredSections = []string{"Role", "Responsibilities", "Output Deliverables"}
+
+// Temperature bounds accepted by supported providers
+const (
+	minTemperature = 0.0
+	maxTemperature = 2.0
+	// Temperatures above this are valid but unusual for coding agents
+	highTemperature = 1.0
+)
+
+// modelPattern matches "provider/model" identifiers
+var modelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*/[A-Za-z0-9][A-Za-z0-9._:@-]*$`)
+
+// LintPath lints a single agent file or every agent file in a directory
+func LintPath(path string) ([]Diagnostic, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return LintFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent directory: %w", err)
+	}
+
+	var diags []Diagnostic
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" || entry.Name() == "README.md" {
+			continue
+		}
+		fileDiags, err := LintFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		diags = append(diags, fileDiags...)
+	}
+
+	return diags, nil
+}
+
+// LintFile lints an agent markdown file on disk
+func LintFile(path string) ([]Diagnostic, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent file: %w", err)
+	}
+	return LintContent(path, string(content)), nil
+}
+
+// LintContent lints agent markdown content, attributing diagnostics to file
+func LintContent(file, content string) []Diagnostic {
+	l := &linter{file: file}
+
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		line := 1
+		var fmErr *frontmatter.Error
+		if errors.As(err, &fmErr) && fmErr.Line > 0 {
+			line = fmErr.Line
+		}
+		l.errorf(line, "frontmatter", "invalid frontmatter: %s", strings.TrimPrefix(err.Error(), fmt.Sprintf("line %d: ", line)))
+		return l.diags
+	}
+
+	if !doc.HasFrontmatter {
+		l.warnf(1, "frontmatter", "no frontmatter; mode, temperature and tools fall back to defaults")
+	} else {
+		l.checkFrontmatter(doc)
+	}
+	l.checkSections(doc)
+	l.checkTemplate(doc)
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		return l.diags[i].Line < l.diags[j].Line
+	})
+	return l.diags
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// linter accumulates diagnostics for one file
+type linter struct {
+	file  string
+	diags []Diagnostic
+}
+
+func (l *linter) errorf(line int, rule, format string, args ...interface{}) {
+	l.add(line, SeverityError, rule, format, args...)
+}
+
+func (l *linter) warnf(line int, rule, format string, args ...interface{}) {
+	l.add(line, SeverityWarning, rule, format, args...)
+}
+
+func (l *linter) add(line int, severity Severity, rule, format string, args ...interface{}) {
+	l.diags = append(l.diags, Diagnostic{
+		File:     l.file,
+		Line:     line,
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkFrontmatter validates frontmatter field values
+func (l *linter) checkFrontmatter(doc *frontmatter.Document) {
+	fm := doc.Frontmatter
+
+	// Unknown keys are preserved but usually indicate a typo
+	var unknown []string
+	for key := range fm.Extra {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		l.warnf(doc.Line(key), "schema", "unknown frontmatter key %q (known keys: %s)", key, strings.Join(frontmatter.Keys(), ", "))
+	}
+
+	// Agents that extend or override another definition inherit unset fields
+	inherits := fm.Extends != "" || fm.Override
+	if fm.Override && fm.Extends != "" {
+		l.errorf(doc.Line("override"), "extends", "an override file cannot also extend another agent")
+	}
+
+	if strings.TrimSpace(fm.Description) == "" && !inherits {
+		l.warnf(doc.Line("description"), "description", "missing description; one is derived from the Role section")
+	}
+
+	if fm.Mode == "" {
+		if !inherits {
+			l.warnf(doc.Line("mode"), "mode", "missing mode; defaults to %q", resources.DefaultMode)
+		}
+	} else if !resources.IsValidMode(fm.Mode) {
+		l.errorf(doc.Line("mode"), "mode", "unknown mode %q; must be %q or %q", fm.Mode, resources.ModePrimary, resources.ModeSubagent)
+	}
+
+	if fm.Temperature != nil {
+		temp := *fm.Temperature
+		switch {
+		case temp < minTemperature || temp > maxTemperature:
+			l.errorf(doc.Line("temperature"), "temperature", "temperature %.2f out of range %.1f-%.1f", temp, minTemperature, maxTemperature)
+		case temp > highTemperature:
+			l.warnf(doc.Line("temperature"), "temperature", "temperature %.2f is unusually high for a coding agent", temp)
+		}
+	}
+
+	if fm.Model != "" && !modelPattern.MatchString(fm.Model) {
+		l.errorf(doc.Line("model"), "model", "model %q must have the form provider/model", fm.Model)
+	}
+
+	var tools []string
+	for tool := range fm.Tools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		if !resources.IsKnownTool(tool) {
+			l.errorf(doc.Line("tools."+tool), "tools", "unknown tool %q (known tools: %s)", tool, strings.Join(resources.KnownTools, ", "))
+		}
+	}
+
+	for _, source := range fm.ProjectContext {
+		if !project.IsValidSource(source) {
+			l.errorf(doc.Line("project_context"), "project-context", "unknown project context source %q", source)
+		}
+	}
+	if fm.ContextBudget < 0 {
+		l.errorf(doc.Line("context_budget"), "project-context", "context_budget must not be negative")
+	}
+
+	var headings []string
+	for heading := range fm.Sections {
+		headings = append(headings, heading)
+	}
+	sort.Strings(headings)
+	for _, heading := range headings {
+		if !inherits {
+			l.warnf(doc.Line("sections"), "extends", "section override %q has no effect without extends", heading)
+		}
+		if mode := fm.Sections[heading]; !resources.IsValidSectionMode(mode) {
+			l.errorf(doc.Line("sections."+heading), "extends", "invalid mode %q for section %q; must be %q, %q or %q",
+				mode, heading, resources.SectionAppend, resources.SectionReplace, resources.SectionRemove)
+		}
+	}
+}
+
+// checkTemplate expands the body's template directives as the engine does
+// when it loads the agent, so that unset prompt variables and missing
+// includes are reported. Includes are looked up next to the file, then in
+// the installed agent directories.
+func (l *linter) checkTemplate(doc *frontmatter.Document) {
+	if !tmpl.HasDirectives(doc.Body) {
+		return
+	}
+
+	var promptVars map[string]string
+	resolution := config.DefaultAgentResolution()
+	if effective, err := config.LoadEffective(); err == nil {
+		promptVars = effective.Config.PromptVars
+		if config.ValidateAgentResolution(effective.Config.Settings.AgentResolution) == nil {
+			resolution = effective.Config.Settings.AgentResolution
+		}
+	}
+
+	dirs := []string{filepath.Dir(l.file)}
+	for _, source := range resolution {
+		if source == config.SourceEmbedded {
+			continue
+		}
+		if agentDir, err := config.GetAgentDir(config.Scope(source)); err == nil {
+			dirs = append(dirs, agentDir)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	if _, err := tmpl.Expand(doc.Body, tmpl.ProjectVars(wd, promptVars), tmpl.DirIncluder(dirs...)); err != nil {
+		l.errorf(doc.BodyLine, "template", "template expansion failed: %s", err)
+	}
+}
+
+// checkSections verifies that required markdown sections are present.
+// Agents that extend or override another definition inherit its sections.
+func (l *linter) checkSections(doc *frontmatter.Document) {
+	if doc.Frontmatter.Extends != "" || doc.Frontmatter.Override {
+		return
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(doc.Body, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			present[strings.TrimSpace(strings.TrimPrefix(line, "## "))] = true
+		}
+	}
+
+	for _, section := range RequiredSections {
+		if !present[section] {
+			l.errorf(doc.BodyLine, "sections", "missing required section \"## %s\"", section)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validAgent = `---
+description: Writes Go code
+mode: primary
+model: anthropic/claude-sonnet-4-20250514
+temperature: 0.3
+tools:
+  read: true
+  bash: true
+---
+
+# Go Implementer
+
+## Role
+Writes Go code.
+
+## Responsibilities
+- Implement features
+
+## Output Deliverables
+- Working code
+`
+
+func TestLintContent_Valid(t *testing.T) {
+	if diags := LintContent("valid.md", validAgent); len(diags) != 0 {
+		t.Errorf("LintContent() = %v, want no diagnostics", diags)
+	}
+}
+
+func TestLintContent_Problems(t *testing.T) {
+	content := `---
+description: Broken agent
+mode: orchestrator
+model: claude
+temperature: 2.5
+colour: red
+tools:
+  read: true
+  shell: true
+  mymcp_*: true
+---
+
+# Broken
+
+## Role
+Breaks things.
+`
+
+	diags := LintContent("broken.md", content)
+
+	want := map[string]struct {
+		line     int
+		severity Severity
+	}{
+		"mode":        {3, SeverityError},
+		"model":       {4, SeverityError},
+		"temperature": {5, SeverityError},
+		"schema":      {6, SeverityWarning},
+		"tools":       {9, SeverityError},
+	}
+
+	found := make(map[string]Diagnostic)
+	sections := 0
+	for _, d := range diags {
+		if d.Rule == "sections" {
+			sections++
+			continue
+		}
+		found[d.Rule] = d
+	}
+
+	for rule, w := range want {
+		d, ok := found[rule]
+		if !ok {
+			t.Errorf("missing %s diagnostic in %v", rule, diags)
+			continue
+		}
+		if d.Line != w.line || d.Severity != w.severity {
+			t.Errorf("%s diagnostic = line %d %s, want line %d %s", rule, d.Line, d.Severity, w.line, w.severity)
+		}
+	}
+	if sections != 2 {
+		t.Errorf("got %d missing section diagnostics, want 2", sections)
+	}
+	if !HasErrors(diags) {
+		t.Errorf("HasErrors() = false, want true")
+	}
+}
+
+func TestLintContent_Extends(t *testing.T) {
+	content := "---\nextends: implementer\nsections:\n  Role: append\n  Guidelines: prepend\n---\n\n## Role\nWrites Go code.\n"
+
+	diags := LintContent("go-implementer.md", content)
+	if len(diags) != 1 || diags[0].Rule != "extends" || diags[0].Line != 5 {
+		t.Errorf("LintContent() = %v, want one extends error on line 5", diags)
+	}
+}
+
+func TestLintContent_Template(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "shared"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "shared", "style.md"), []byte("Style\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "agent.md")
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"literal braces", "Set `{{ .Values.image }}` in values.yaml.\n", ""},
+		{"include next to the file", "{{include \"shared/style.md\"}}\n", ""},
+		{"unset variable", "Team: {{.Vars.team}}\n", "prompt variable \"team\" is not set"},
+		{"missing include", "{{include \"shared/none.md\"}}\n", "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := LintContent(file, validAgent+tt.body)
+			if tt.wantErr == "" {
+				if len(diags) != 0 {
+					t.Errorf("LintContent() = %v, want no diagnostics", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Rule != "template" || !strings.Contains(diags[0].Message, tt.wantErr) {
+				t.Errorf("LintContent() = %v, want a template error containing %q", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLintContent_InvalidYAML(t *testing.T) {
+	diags := LintContent("bad.md", "---\ndescription: x\nmode: a: b\n---\n")
+	if len(diags) != 1 || diags[0].Rule != "frontmatter" || diags[0].Line != 3 {
+		t.Errorf("LintContent() = %v, want one frontmatter error on line 3", diags)
+	}
+}
+
+func TestLintPath_EmbeddedAgents(t *testing.T) {
+	diags, err := LintPath("../resources/embed/agents")
+	if err != nil {
+		t.Fatalf("LintPath() error = %v", err)
+	}
+	for _, d := range diags {
+		t.Errorf("embedded agent diagnostic: %s", d)
+	}
+}
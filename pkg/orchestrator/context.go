@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// placeholderPattern matches {{key}} placeholders in step inputs
+var placeholderPattern = regexp.MustCompile(`\{\{([A-Za-z0-9_.-]+)\}\}`)
+
+// stepContextKeys are set by the orchestrator as steps complete
+var stepContextKeys = map[string]bool{
+	"last_output": true,
+	"last_agent":  true,
+}
+
+// Placeholders returns the context keys the workflow's step inputs refer
+// to, excluding those set by earlier steps, in order of first use
+func (w Workflow) Placeholders() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, step := range w.Steps {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(step.Input, -1) {
+			key := m[1]
+			if !seen[key] && !stepContextKeys[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// MissingContext returns the placeholders that have no value in context or
+// in the workflow's default context
+func (w Workflow) MissingContext(context map[string]string) []string {
+	var missing []string
+	for _, key := range w.Placeholders() {
+		_, given := context[key]
+		_, defaulted := w.Context[key]
+		if !given && !defaulted {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// ParseContext decodes a YAML or JSON mapping of workflow context values.
+// Numbers and booleans are converted to strings; nested values are rejected.
+func ParseContext(data []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid context file: %w", err)
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	context := make(map[string]string, len(raw))
+	for _, key := range keys {
+		switch v := raw[key].(type) {
+		case string:
+			context[key] = v
+		case nil:
+			context[key] = ""
+		case bool, int, float64:
+			context[key] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("context value %q must be a string, number or boolean", key)
+		}
+	}
+	return context, nil
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileError reports a user-defined workflow file that could not be loaded
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// LoadWorkflows reads the user-defined workflows in dir, one per .yaml or
+// .yml file. A workflow without a name is named after its file. A missing
+// directory has no workflows.
+func LoadWorkflows(dir string) ([]Workflow, error) {
+	workflows, skipped, err := loadWorkflows(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		return nil, skipped[0]
+	}
+	return workflows, nil
+}
+
+// loadWorkflows reads the workflows in dir like LoadWorkflows, but leaves
+// out files that cannot be read or are not valid workflows and returns
+// them as *FileError in skipped, so one broken file does not hide the others
+func loadWorkflows(dir string) (workflows []Workflow, skipped []error, err error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read workflow directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			skipped = append(skipped, &FileError{Path: path, Err: fmt.Errorf("failed to read workflow file: %w", err)})
+			continue
+		}
+
+		workflow, err := ParseWorkflow(strings.TrimSuffix(entry.Name(), ext), data)
+		if err != nil {
+			skipped = append(skipped, &FileError{Path: path, Err: err})
+			continue
+		}
+		workflow.Source = path
+		workflows = append(workflows, workflow)
+	}
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].Name < workflows[j].Name
+	})
+	return workflows, skipped, nil
+}
+
+// ParseWorkflow decodes and validates a YAML workflow definition. name is
+// used if the definition does not set one.
+func ParseWorkflow(name string, data []byte) (Workflow, error) {
+	var workflow Workflow
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&workflow); err != nil {
+		return Workflow{}, fmt.Errorf("invalid workflow: %w", err)
+	}
+
+	if workflow.Name == "" {
+		workflow.Name = name
+	}
+	if len(workflow.Steps) == 0 {
+		return Workflow{}, errors.New("invalid workflow: no steps")
+	}
+	for i, step := range workflow.Steps {
+		if step.AgentName == "" {
+			return Workflow{}, fmt.Errorf("invalid workflow: step %d has no agent_name", i+1)
+		}
+		if strings.TrimSpace(step.Input) == "" {
+			return Workflow{}, fmt.Errorf("invalid workflow: step %d has no input", i+1)
+		}
+	}
+	return workflow, nil
+}
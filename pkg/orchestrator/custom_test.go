@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string // Workflow name
+		wantErr string
+	}{
+		{
+			name: "named after file",
+			data: "description: Review\nsteps:\n  - agent_name: reviewer\n    input: \"Review {{changes}}\"\n",
+			want: "review",
+		},
+		{
+			name: "explicit name",
+			data: "name: check\nsteps:\n  - agent_name: reviewer\n    input: Review\n",
+			want: "check",
+		},
+		{
+			name:    "no steps",
+			data:    "name: empty\n",
+			wantErr: "no steps",
+		},
+		{
+			name:    "step without agent",
+			data:    "steps:\n  - input: Review\n",
+			wantErr: "step 1 has no agent_name",
+		},
+		{
+			name:    "unknown field",
+			data:    "steps:\n  - agent: reviewer\n    input: Review\n",
+			wantErr: "field agent not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWorkflow("review", []byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseWorkflow() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWorkflow() error = %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("Name = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestListWorkflows_UserDefined(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	files := map[config.Scope]map[string]string{
+		config.UserScope: {
+			"review.yaml": "description: User review\nsteps:\n  - agent_name: reviewer\n    input: Review\n",
+		},
+		config.ProjectScope: {
+			"review.yml":   "description: Project review\nsteps:\n  - agent_name: reviewer\n    input: Review\n",
+			"bug-fix.yaml": "description: Project bug fix\nsteps:\n  - agent_name: debugger\n    input: \"Debug {{bug}}\"\n",
+			"notes.txt":    "not a workflow",
+			"broken.yaml":  "steps: [\n",
+		},
+	}
+	for scope, workflows := range files {
+		dir, err := config.GetWorkflowDir(scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range workflows {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	workflows, skipped := ListWorkflowsWithErrors()
+	if len(skipped) != 1 || !strings.Contains(skipped[0].Error(), "broken.yaml") {
+		t.Errorf("ListWorkflowsWithErrors() skipped = %v, want broken.yaml", skipped)
+	}
+
+	var got []string
+	for _, w := range workflows {
+		got = append(got, w.Name+": "+w.Description)
+	}
+	want := []string{
+		"new-feature: " + NewFeatureWorkflow.Description,
+		"bug-fix: Project bug fix",
+		"code-improvement: " + CodeImprovementWorkflow.Description,
+		"review: Project review",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ListWorkflowsWithErrors() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if listed, err := ListWorkflows(); err != nil || len(listed) != len(workflows) {
+		t.Errorf("ListWorkflows() = %d workflows, %v, want %d without an error", len(listed), err, len(workflows))
+	}
+	projectDir, _ := config.GetWorkflowDir(config.ProjectScope)
+	if _, err := LoadWorkflows(projectDir); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("LoadWorkflows() error = %v, want the broken.yaml error", err)
+	}
+
+	workflow, err := GetWorkflow("review")
+	if err != nil || !strings.HasSuffix(workflow.Source, "review.yml") {
+		t.Errorf("GetWorkflow(review) = %+v, %v, want the project workflow", workflow, err)
+	}
+	if _, err := GetWorkflow("code-improvement"); err != nil {
+		t.Errorf("GetWorkflow(code-improvement) error = %v, want the built-in workflow", err)
+	}
+	if _, err := GetWorkflow("broken"); err == nil || !strings.Contains(err.Error(), "invalid workflow") {
+		t.Errorf("GetWorkflow(broken) error = %v, want the file's error", err)
+	}
+}
@@ -2,31 +2,65 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
 )
 
 // Workflow represents a multi-agent execution workflow
 type Workflow struct {
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	Steps       []WorkflowStep    `json:"steps"`
-	Context     map[string]string `json:"context,omitempty"`
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Steps       []WorkflowStep    `json:"steps" yaml:"steps"`
+	Context     map[string]string `json:"context,omitempty" yaml:"context,omitempty"` // Default context values
+	Source      string            `json:"source,omitempty" yaml:"-"`                  // File a user-defined workflow was loaded from
 }
 
 // WorkflowStep represents a single step in a workflow
 type WorkflowStep struct {
-	AgentName string            `json:"agent_name"`
-	Input     string            `json:"input"`
-	Required  bool              `json:"required"` // Whether this step must succeed
-	Context   map[string]string `json:"context,omitempty"`
+	AgentName string            `json:"agent_name" yaml:"agent_name"`
+	Input     string            `json:"input" yaml:"input"`
+	Required  bool              `json:"required" yaml:"required"` // Whether this step must succeed
+	Context   map[string]string `json:"context,omitempty" yaml:"context,omitempty"`
 }
 
 // Orchestrator manages multi-agent workflows
 type Orchestrator struct {
 	engine *agent.Engine
+
+	// Events, if set, receives progress as workflow steps run
+	Events func(Event)
+}
+
+// StepStatus is the state of a workflow step
+type StepStatus string
+
+// Workflow step states
+const (
+	StepPending   StepStatus = "pending"
+	StepRunning   StepStatus = "running"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+)
+
+// Event reports the progress of a workflow step
+type Event struct {
+	Step   int         // Index of the step in the workflow
+	Status StepStatus  // Step state after the event
+	Output string      // Output chunk while the step is running
+	Result *StepResult // Set once the step has finished
+}
+
+// emit sends an event to the observer, if any
+func (o *Orchestrator) emit(e Event) {
+	if o.Events != nil {
+		o.Events(e)
+	}
 }
 
 // NewOrchestrator creates a new orchestrator
@@ -36,6 +70,17 @@ func NewOrchestrator() *Orchestrator {
 	}
 }
 
+// LoadOrchestrator creates a new orchestrator like NewOrchestrator, but
+// returns an error if the configuration cannot be loaded instead of using
+// the defaults
+func LoadOrchestrator() (*Orchestrator, error) {
+	engine, err := agent.LoadEngine()
+	if err != nil {
+		return nil, err
+	}
+	return &Orchestrator{engine: engine}, nil
+}
+
 // ExecuteWorkflow runs a complete workflow
 func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflow Workflow) (*WorkflowResult, error) {
 	result := &WorkflowResult{
@@ -52,9 +97,24 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflow Workflow) (
 
 	// Execute each step
 	for i, step := range workflow.Steps {
-		stepResult := o.executeStep(ctx, step, result.Context)
+		if err := ctx.Err(); err != nil {
+			result.Success = false
+			result.Error = fmt.Sprintf("Workflow stopped before step %d (%s): %v", i+1, step.AgentName, err)
+			return result, nil
+		}
+
+		o.emit(Event{Step: i, Status: StepRunning})
+		stepResult := o.executeStep(ctx, step, result.Context, func(chunk string) {
+			o.emit(Event{Step: i, Status: StepRunning, Output: chunk})
+		})
 		result.Steps = append(result.Steps, stepResult)
 
+		status := StepSucceeded
+		if !stepResult.Success {
+			status = StepFailed
+		}
+		o.emit(Event{Step: i, Status: status, Result: &stepResult})
+
 		// Update context with step results
 		if stepResult.Success {
 			result.Context["last_output"] = stepResult.Output
@@ -78,8 +138,8 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflow Workflow) (
 			// Verify handoff matches next step
 			nextStep := workflow.Steps[i+1]
 			if stepResult.Handoff.AgentName != nextStep.AgentName {
-				// Log warning but continue
-				fmt.Printf("Warning: Handoff suggested %s but next step is %s\n",
+				// Log warning to stderr, leaving stdout to the workflow result
+				fmt.Fprintf(os.Stderr, "Warning: Handoff suggested %s but next step is %s\n",
 					stepResult.Handoff.AgentName, nextStep.AgentName)
 			}
 		}
@@ -88,8 +148,8 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflow Workflow) (
 	return result, nil
 }
 
-// executeStep executes a single workflow step
-func (o *Orchestrator) executeStep(ctx context.Context, step WorkflowStep, workflowCtx map[string]interface{}) StepResult {
+// executeStep executes a single workflow step, passing output chunks to stream
+func (o *Orchestrator) executeStep(ctx context.Context, step WorkflowStep, workflowCtx map[string]interface{}, stream func(string)) StepResult {
 	// Prepare input with context substitution
 	input := o.substituteContext(step.Input, workflowCtx)
 
@@ -98,6 +158,7 @@ func (o *Orchestrator) executeStep(ctx context.Context, step WorkflowStep, workf
 		AgentName: step.AgentName,
 		Input:     input,
 		Context:   workflowCtx,
+		Stream:    stream,
 	}
 
 	// Execute agent
@@ -119,6 +180,7 @@ func (o *Orchestrator) executeStep(ctx context.Context, step WorkflowStep, workf
 		Error:     response.Error,
 		Context:   response.Context,
 		Handoff:   response.Handoff,
+		Usage:     response.Usage,
 	}
 }
 
@@ -156,6 +218,7 @@ type StepResult struct {
 	Error     string                   `json:"error,omitempty"`
 	Context   map[string]interface{}   `json:"context,omitempty"`
 	Handoff   *agent.HandoffSuggestion `json:"handoff,omitempty"`
+	Usage     *agent.Usage             `json:"usage,omitempty"`
 }
 
 // Predefined workflows
@@ -250,25 +313,69 @@ var (
 	}
 )
 
-// GetWorkflow returns a predefined workflow by name
+// GetWorkflow returns a predefined or user-defined workflow by name. If
+// no workflow has the name but a workflow file named after it could not be
+// loaded, that file's error is returned.
 func GetWorkflow(name string) (Workflow, error) {
-	switch name {
-	case "new-feature":
-		return NewFeatureWorkflow, nil
-	case "bug-fix":
-		return BugFixWorkflow, nil
-	case "code-improvement":
-		return CodeImprovementWorkflow, nil
-	default:
-		return Workflow{}, fmt.Errorf("workflow '%s' not found", name)
+	workflows, skipped := ListWorkflowsWithErrors()
+	for _, workflow := range workflows {
+		if workflow.Name == name {
+			return workflow, nil
+		}
+	}
+	for _, err := range skipped {
+		var fileErr *FileError
+		if errors.As(err, &fileErr) && strings.TrimSuffix(filepath.Base(fileErr.Path), filepath.Ext(fileErr.Path)) == name {
+			return Workflow{}, err
+		}
 	}
+	return Workflow{}, fmt.Errorf("workflow '%s' not found", name)
+}
+
+// ListWorkflows returns the predefined workflows followed by the workflows
+// defined in the user and project scopes. A user-defined workflow replaces
+// a predefined one of the same name, and project workflows replace user ones.
+// Workflow files that cannot be loaded are left out; use
+// ListWorkflowsWithErrors to report them.
+func ListWorkflows() ([]Workflow, error) {
+	workflows, _ := ListWorkflowsWithErrors()
+	return workflows, nil
 }
 
-// ListWorkflows returns all available predefined workflows
-func ListWorkflows() []Workflow {
-	return []Workflow{
+// ListWorkflowsWithErrors returns the workflows like ListWorkflows, along
+// with the errors of the workflow files and directories left out
+func ListWorkflowsWithErrors() (workflows []Workflow, skipped []error) {
+	workflows = []Workflow{
 		NewFeatureWorkflow,
 		BugFixWorkflow,
 		CodeImprovementWorkflow,
 	}
+
+	index := make(map[string]int)
+	for i, workflow := range workflows {
+		index[workflow.Name] = i
+	}
+
+	for _, scope := range []config.Scope{config.UserScope, config.ProjectScope} {
+		dir, err := config.GetWorkflowDir(scope)
+		if err != nil {
+			continue
+		}
+		custom, invalid, err := loadWorkflows(dir)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: %w", dir, err))
+			continue
+		}
+		skipped = append(skipped, invalid...)
+		for _, workflow := range custom {
+			if i, ok := index[workflow.Name]; ok {
+				workflows[i] = workflow
+				continue
+			}
+			index[workflow.Name] = len(workflows)
+			workflows = append(workflows, workflow)
+		}
+	}
+
+	return workflows, skipped
 }
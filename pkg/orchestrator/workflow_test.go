@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"context"
+	"reflect"
 	"testing"
 )
 
@@ -71,6 +72,57 @@ func TestOrchestrator_ExecuteWorkflow(t *testing.T) {
 	}
 }
 
+func TestOrchestrator_Events(t *testing.T) {
+	orch := NewOrchestrator()
+	workflow := Workflow{
+		Name: "events",
+		Steps: []WorkflowStep{
+			{AgentName: "implementer", Input: "Implement it", Required: true},
+			{AgentName: "tester", Input: "Test: {{last_output}}", Required: true},
+		},
+	}
+
+	var events []Event
+	output := make(map[int]string)
+	orch.Events = func(e Event) {
+		events = append(events, e)
+		output[e.Step] += e.Output
+	}
+
+	result, err := orch.ExecuteWorkflow(context.Background(), workflow)
+	if err != nil || !result.Success {
+		t.Fatalf("ExecuteWorkflow() = %+v, %v", result, err)
+	}
+
+	for i, step := range result.Steps {
+		if output[i] != step.Output {
+			t.Errorf("streamed output of step %d = %q, want %q", i, output[i], step.Output)
+		}
+		if step.Usage == nil || step.Usage.InputTokens == 0 || step.Usage.OutputTokens == 0 {
+			t.Errorf("step %d usage = %+v, want token estimates", i, step.Usage)
+		}
+	}
+
+	if first := events[0]; first.Step != 0 || first.Status != StepRunning {
+		t.Errorf("first event = %+v, want step 0 running", first)
+	}
+	last := events[len(events)-1]
+	if last.Step != 1 || last.Status != StepSucceeded || last.Result == nil {
+		t.Errorf("last event = %+v, want step 1 succeeded with result", last)
+	}
+
+	// A cancelled workflow stops before running any further steps
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err = orch.ExecuteWorkflow(ctx, workflow)
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if result.Success || len(result.Steps) != 0 {
+		t.Errorf("cancelled workflow = %+v, want failure with no steps", result)
+	}
+}
+
 func TestSubstituteContext(t *testing.T) {
 	orch := NewOrchestrator()
 
@@ -115,3 +167,74 @@ func TestSubstituteContext(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflow_MissingContext(t *testing.T) {
+	if got := BugFixWorkflow.Placeholders(); !reflect.DeepEqual(got, []string{"bug_description"}) {
+		t.Errorf("Placeholders() = %v, want [bug_description]", got)
+	}
+
+	missing := BugFixWorkflow.MissingContext(map[string]string{"feature_description": "x"})
+	if !reflect.DeepEqual(missing, []string{"bug_description"}) {
+		t.Errorf("MissingContext() = %v, want [bug_description]", missing)
+	}
+	if missing := BugFixWorkflow.MissingContext(map[string]string{"bug_description": ""}); len(missing) != 0 {
+		t.Errorf("MissingContext() = %v, want none", missing)
+	}
+
+	defaulted := BugFixWorkflow
+	defaulted.Context = map[string]string{"bug_description": "default"}
+	if missing := defaulted.MissingContext(nil); len(missing) != 0 {
+		t.Errorf("MissingContext() = %v, want none with a default value", missing)
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			data: "bug_description: |\n  Login fails, with a comma\n  on two lines\nretries: 3\nurgent: true\n",
+			want: map[string]string{
+				"bug_description": "Login fails, with a comma\non two lines\n",
+				"retries":         "3",
+				"urgent":          "true",
+			},
+		},
+		{
+			name: "json",
+			data: `{"code_location": "pkg/auth", "empty": null}`,
+			want: map[string]string{"code_location": "pkg/auth", "empty": ""},
+		},
+		{
+			name: "empty",
+			data: "",
+			want: map[string]string{},
+		},
+		{
+			name:    "nested value",
+			data:    "bug:\n  title: x\n",
+			wantErr: true,
+		},
+		{
+			name:    "not a mapping",
+			data:    "- a\n- b\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseContext([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package project
+
+// Commands returns the usual build, test and lint commands for a project,
+// keyed "build_command", "test_command" and "lint_command". Commands that
+// have no common default for the language are left out.
+func Commands(dir string, info Info) map[string]string {
+	commands := make(map[string]string)
+
+	switch info.Language {
+	case LanguageGo:
+		commands["build_command"] = "go build ./..."
+		commands["test_command"] = "go test ./..."
+		commands["lint_command"] = "go vet ./..."
+	case LanguageJavaScript, LanguageTypeScript:
+		pm := nodePackageManager(dir)
+		commands["build_command"] = pm + " run build"
+		commands["test_command"] = pm + " test"
+		commands["lint_command"] = pm + " run lint"
+	case LanguagePython:
+		commands["test_command"] = "pytest"
+		commands["lint_command"] = "ruff check ."
+	case LanguageRust:
+		commands["build_command"] = "cargo build"
+		commands["test_command"] = "cargo test"
+		commands["lint_command"] = "cargo clippy"
+	}
+
+	return commands
+}
+
+// nodePackageManager returns the package manager whose lock file is in dir
+func nodePackageManager(dir string) string {
+	switch {
+	case fileExists(dir, "pnpm-lock.yaml"):
+		return "pnpm"
+	case fileExists(dir, "yarn.lock"):
+		return "yarn"
+	case fileExists(dir, "bun.lockb"):
+		return "bun"
+	default:
+		return "npm"
+	}
+}
@@ -0,0 +1,54 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		info  Info
+		files []string
+		want  map[string]string
+	}{
+		{
+			name: "go",
+			info: Info{Language: LanguageGo},
+			want: map[string]string{"build_command": "go build ./...", "test_command": "go test ./...", "lint_command": "go vet ./..."},
+		},
+		{
+			name:  "typescript with pnpm",
+			info:  Info{Language: LanguageTypeScript},
+			files: []string{"pnpm-lock.yaml"},
+			want:  map[string]string{"build_command": "pnpm run build", "test_command": "pnpm test", "lint_command": "pnpm run lint"},
+		},
+		{
+			name: "python",
+			info: Info{Language: LanguagePython},
+			want: map[string]string{"test_command": "pytest", "lint_command": "ruff check ."},
+		},
+		{
+			name: "unknown",
+			info: Info{},
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := Commands(dir, tt.info); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Commands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/repomap"
+)
+
+// Context sources that an agent can opt into
+const (
+	SourceDocs    = "docs"    // AGENTS.md, CONTRIBUTING.md and README.md contents
+	SourceTree    = "tree"    // Directory tree respecting .gitignore
+	SourceGoMod   = "gomod"   // Go module path, Go version and dependency count
+	SourceGit     = "git"     // Current branch and working tree status
+	SourceRepoMap = "repomap" // Go symbol map of exported packages, types and funcs
+)
+
+// DefaultTokenBudget caps project context when an agent sets no budget
+const DefaultTokenBudget = 4000
+
+// charsPerToken approximates the size of a token for budgeting
+const charsPerToken = 4
+
+// maxTreeDepth limits how deep the directory tree is rendered
+const maxTreeDepth = 3
+
+// maxTreeEntries limits how many entries the directory tree shows
+const maxTreeEntries = 200
+
+// docFiles are the documentation files included by the docs source, in order
+var docFiles = []string{"AGENTS.md", "CONTRIBUTING.md", "README.md"}
+
+// Options selects which project context is gathered
+type Options struct {
+	Sources     []string // Sources to include, in order
+	TokenBudget int      // Maximum estimated tokens; DefaultTokenBudget if zero
+}
+
+// Section is one titled block of project context
+type Section struct {
+	Title   string
+	Content string
+}
+
+// IsValidSource reports whether name is a known context source
+func IsValidSource(name string) bool {
+	switch name {
+	case SourceDocs, SourceTree, SourceGoMod, SourceGit, SourceRepoMap:
+		return true
+	default:
+		return false
+	}
+}
+
+// Gather collects the requested project context for dir and renders it as
+// markdown, truncating sections so the result stays within the token budget.
+func Gather(dir string, opts Options) string {
+	if len(opts.Sources) == 0 {
+		return ""
+	}
+
+	var sections []Section
+	for _, source := range opts.Sources {
+		switch source {
+		case SourceDocs:
+			sections = append(sections, docSections(dir)...)
+		case SourceTree:
+			if tree := directoryTree(dir); tree != "" {
+				sections = append(sections, Section{Title: "Directory Tree", Content: tree})
+			}
+		case SourceGoMod:
+			if mod := goModuleInfo(dir); mod != "" {
+				sections = append(sections, Section{Title: "Go Module", Content: mod})
+			}
+		case SourceGit:
+			if status := gitStatus(dir); status != "" {
+				sections = append(sections, Section{Title: "Git Status", Content: status})
+			}
+		case SourceRepoMap:
+			if m, err := repomap.Build(dir); err == nil {
+				sections = append(sections, Section{Title: "Go Repository Map", Content: m.String()})
+			}
+		}
+	}
+
+	budget := opts.TokenBudget
+	if budget <= 0 {
+		budget = DefaultTokenBudget
+	}
+
+	return Render(sections, budget)
+}
+
+// Render formats sections as markdown within a token budget. Sections that
+// do not fit are truncated; once the budget is spent the rest are dropped.
+func Render(sections []Section, tokenBudget int) string {
+	if len(sections) == 0 {
+		return ""
+	}
+
+	const header = "# Project Context\n"
+	remaining := tokenBudget*charsPerToken - len(header)
+
+	var b strings.Builder
+	b.WriteString(header)
+	for _, section := range sections {
+		title := fmt.Sprintf("\n## %s\n\n", section.Title)
+		if remaining <= len(title) {
+			break
+		}
+		remaining -= len(title)
+
+		content := strings.TrimRight(section.Content, "\n") + "\n"
+		if len(content) > remaining {
+			const marker = "\n... (truncated)\n"
+			cut := remaining - len(marker)
+			// Back up to a rune boundary so the text stays valid UTF-8
+			for cut > 0 && !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			if cut <= 0 {
+				break
+			}
+			content = content[:cut] + marker
+		}
+		remaining -= len(content)
+
+		b.WriteString(title)
+		b.WriteString(content)
+	}
+
+	return b.String()
+}
+
+// docSections reads the project documentation files that exist in dir
+func docSections(dir string) []Section {
+	var sections []Section
+	for _, name := range docFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		sections = append(sections, Section{Title: name, Content: string(data)})
+	}
+	return sections
+}
+
+// ListFiles returns project files relative to dir, excluding ignored paths.
+// It asks git when dir is a repository and falls back to walking the tree
+// with the root .gitignore rules otherwise.
+func ListFiles(dir string) ([]string, error) {
+	out, err := runGit(dir, "ls-files", "--cached", "--others", "--exclude-standard")
+	if err == nil {
+		var files []string
+		for _, line := range strings.Split(out, "\n") {
+			if line != "" {
+				files = append(files, line)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
+	ignorer := LoadIgnorer(dir)
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if ignorer.Ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk project directory: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// directoryTree renders the project files as an indented tree
+func directoryTree(dir string) string {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return ""
+	}
+
+	seen := make(map[string]bool)
+	var b strings.Builder
+	entries := 0
+	for _, file := range files {
+		parts := strings.Split(file, "/")
+		for depth := range parts {
+			if depth >= maxTreeDepth {
+				break
+			}
+			key := strings.Join(parts[:depth+1], "/")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if entries >= maxTreeEntries {
+				b.WriteString("...\n")
+				return b.String()
+			}
+			entries++
+
+			name := parts[depth]
+			if depth < len(parts)-1 {
+				name += "/"
+			}
+			b.WriteString(strings.Repeat("  ", depth) + name + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// goModuleInfo summarises the go.mod file in dir
+func goModuleInfo(dir string) string {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var module, goVersion string
+	requires := 0
+	inRequire := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "module "):
+			module = strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		case strings.HasPrefix(line, "go "):
+			goVersion = strings.TrimSpace(strings.TrimPrefix(line, "go "))
+		case line == "require (":
+			inRequire = true
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire && line != "" && !strings.HasPrefix(line, "//"):
+			requires++
+		case strings.HasPrefix(line, "require "):
+			requires++
+		}
+	}
+
+	if module == "" {
+		return ""
+	}
+
+	info := fmt.Sprintf("Module: %s\n", module)
+	if goVersion != "" {
+		info += fmt.Sprintf("Go version: %s\n", goVersion)
+	}
+	info += fmt.Sprintf("Dependencies: %d\n", requires)
+	return info
+}
+
+// gitStatus reports the current branch and short working tree status
+func gitStatus(dir string) string {
+	branch, err := runGit(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+
+	status, err := runGit(dir, "status", "--short")
+	if err != nil {
+		return ""
+	}
+
+	info := fmt.Sprintf("Branch: %s\n", strings.TrimSpace(branch))
+	if strings.TrimSpace(status) == "" {
+		info += "Working tree clean\n"
+	} else {
+		info += "Changes:\n" + status
+	}
+	return info
+}
+
+// runGit runs a git command in dir and returns its output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
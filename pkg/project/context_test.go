@@ -0,0 +1,113 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestIgnorer_Ignored(t *testing.T) {
+	ig := &Ignorer{}
+	for _, line := range []string{"# comment", "*.log", "build/", "/vendor", "docs/*.tmp", "!keep.log", "**/cache", "**/gen/*.pb.go"} {
+		ig.Add(line)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"nested/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"vendor", true, true},
+		{"pkg/vendor", true, false},
+		{"docs/a.tmp", false, true},
+		{"other/a.tmp", false, false},
+		{".git", true, true},
+		{"cache", true, true},
+		{"src/cache", true, true},
+		{"gen/api.pb.go", false, true},
+		{"internal/gen/api.pb.go", false, true},
+		{"internal/api.pb.go", false, false},
+		{"main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := ig.Ignored(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Ignorer.Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "AGENTS.md", "Always run go vet.")
+	writeFile(t, dir, "go.mod", "module example.com/demo\n\ngo 1.21\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n)\n")
+	writeFile(t, dir, ".gitignore", "dist/\n")
+	writeFile(t, dir, "cmd/demo/main.go", "package main")
+	writeFile(t, dir, "dist/demo", "binary")
+
+	out := Gather(dir, Options{Sources: []string{SourceDocs, SourceGoMod, SourceTree}})
+
+	for _, want := range []string{"## AGENTS.md", "Always run go vet.", "Module: example.com/demo", "Dependencies: 1", "cmd/", "    main.go"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Gather() missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "dist/") {
+		t.Errorf("Gather() tree includes ignored directory:\n%s", out)
+	}
+}
+
+func TestGather_NoSources(t *testing.T) {
+	if out := Gather(t.TempDir(), Options{}); out != "" {
+		t.Errorf("Gather() without sources = %q, want empty", out)
+	}
+}
+
+func TestRender_TokenBudget(t *testing.T) {
+	sections := []Section{
+		{Title: "First", Content: strings.Repeat("a", 1000)},
+		{Title: "Second", Content: "never shown"},
+	}
+
+	out := Render(sections, 50)
+
+	if len(out) > 50*charsPerToken {
+		t.Errorf("Render() length = %d, want at most %d", len(out), 50*charsPerToken)
+	}
+	if !strings.Contains(out, "(truncated)") {
+		t.Errorf("Render() did not mark truncation:\n%s", out)
+	}
+	if strings.Contains(out, "Second") {
+		t.Errorf("Render() included section beyond budget:\n%s", out)
+	}
+}
+
+func TestRender_TruncatesOnRuneBoundary(t *testing.T) {
+	sections := []Section{{Title: "Notes", Content: strings.Repeat("é", 500)}}
+
+	for budget := 10; budget < 20; budget++ {
+		if out := Render(sections, budget); !utf8.ValidString(out) {
+			t.Errorf("Render(budget %d) returned invalid UTF-8:\n%q", budget, out)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,215 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Languages reported by Detect
+const (
+	LanguageGo         = "Go"
+	LanguageJavaScript = "JavaScript"
+	LanguageTypeScript = "TypeScript"
+	LanguagePython     = "Python"
+	LanguageRust       = "Rust"
+)
+
+// Info describes a project as detected from its manifest files
+type Info struct {
+	Name      string `json:"name"`
+	Language  string `json:"language,omitempty"`
+	Framework string `json:"framework,omitempty"`
+	Manifest  string `json:"manifest,omitempty"` // Manifest file the language was detected from
+}
+
+// frameworks maps dependency names to framework names, per language
+var frameworks = map[string][][2]string{
+	LanguageGo: {
+		{"github.com/gin-gonic/gin", "Gin"},
+		{"github.com/labstack/echo", "Echo"},
+		{"github.com/gofiber/fiber", "Fiber"},
+		{"github.com/go-chi/chi", "Chi"},
+		{"github.com/spf13/cobra", "Cobra"},
+	},
+	LanguageJavaScript: {
+		{"next", "Next.js"},
+		{"@angular/core", "Angular"},
+		{"vue", "Vue"},
+		{"svelte", "Svelte"},
+		{"react", "React"},
+		{"express", "Express"},
+	},
+	LanguagePython: {
+		{"django", "Django"},
+		{"fastapi", "FastAPI"},
+		{"flask", "Flask"},
+	},
+	LanguageRust: {
+		{"actix-web", "Actix Web"},
+		{"axum", "Axum"},
+		{"rocket", "Rocket"},
+	},
+}
+
+// Detect inspects the manifest files in dir to determine the project name,
+// language and framework. The directory name is used when no manifest names
+// the project.
+func Detect(dir string) Info {
+	info := Info{Name: filepath.Base(dir)}
+	if abs, err := filepath.Abs(dir); err == nil {
+		info.Name = filepath.Base(abs)
+	}
+
+	switch {
+	case fileExists(dir, "go.mod"):
+		detectGo(dir, &info)
+	case fileExists(dir, "package.json"):
+		detectNode(dir, &info)
+	case fileExists(dir, "pyproject.toml"):
+		detectTOML(dir, "pyproject.toml", LanguagePython, []string{"project", "tool.poetry"}, &info)
+	case fileExists(dir, "Cargo.toml"):
+		detectTOML(dir, "Cargo.toml", LanguageRust, []string{"package"}, &info)
+	}
+
+	return info
+}
+
+// detectGo reads the module path and dependencies from go.mod
+func detectGo(dir string, info *Info) {
+	info.Language = LanguageGo
+	info.Manifest = "go.mod"
+
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			module := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+			info.Name = module[strings.LastIndex(module, "/")+1:]
+			break
+		}
+	}
+
+	info.Framework = matchFramework(LanguageGo, func(dep string) bool {
+		return strings.Contains(string(data), dep)
+	})
+}
+
+// detectNode reads the package name and dependencies from package.json
+func detectNode(dir string, info *Info) {
+	info.Language = LanguageJavaScript
+	info.Manifest = "package.json"
+
+	var pkg struct {
+		Name            string            `json:"name"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil || json.Unmarshal(data, &pkg) != nil {
+		return
+	}
+
+	if pkg.Name != "" {
+		info.Name = pkg.Name
+	}
+
+	_, hasTS := pkg.DevDependencies["typescript"]
+	if _, ok := pkg.Dependencies["typescript"]; ok || hasTS || fileExists(dir, "tsconfig.json") {
+		info.Language = LanguageTypeScript
+	}
+
+	info.Framework = matchFramework(LanguageJavaScript, func(dep string) bool {
+		_, ok := pkg.Dependencies[dep]
+		_, dev := pkg.DevDependencies[dep]
+		return ok || dev
+	})
+}
+
+// detectTOML reads the project name from the first matching table of a TOML
+// manifest and looks for framework dependencies anywhere in the file
+func detectTOML(dir, manifest, language string, tables []string, info *Info) {
+	info.Language = language
+	info.Manifest = manifest
+
+	f, err := os.Open(filepath.Join(dir, manifest))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var table string
+	named := false
+	deps := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+
+		// Entries of a multi-line dependencies array, such as "fastapi>=0.100"
+		if strings.HasPrefix(line, `"`) || strings.HasPrefix(line, "'") {
+			deps[dependencyName(line)] = true
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+
+		if key == "name" && !named {
+			for _, t := range tables {
+				if table == t {
+					info.Name = strings.Trim(strings.TrimSpace(value), `"'`)
+					named = true
+				}
+			}
+		}
+		if strings.Contains(table, "dependencies") {
+			deps[strings.ToLower(key)] = true
+		}
+		for _, entry := range strings.Split(strings.Trim(strings.TrimSpace(value), "[]"), ",") {
+			deps[dependencyName(entry)] = true
+		}
+	}
+
+	info.Framework = matchFramework(language, func(dep string) bool {
+		return deps[dep]
+	})
+}
+
+// dependencyName extracts a lowercase package name from a requirement
+// string such as "Django>=4.2"
+func dependencyName(requirement string) string {
+	name := strings.Trim(strings.TrimSpace(requirement), `"',`)
+	if i := strings.IndexAny(name, "<>=!~[; "); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+// matchFramework returns the first framework of language whose dependency is present
+func matchFramework(language string, has func(dep string) bool) string {
+	for _, fw := range frameworks[language] {
+		if has(fw[0]) {
+			return fw[1]
+		}
+	}
+	return ""
+}
+
+// fileExists reports whether name exists in dir
+func fileExists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
@@ -0,0 +1,70 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Info
+	}{
+		{
+			name:  "go module",
+			files: map[string]string{"go.mod": "module github.com/acme/widgets\n\ngo 1.21\n\nrequire github.com/spf13/cobra v1.8.0\n"},
+			want:  Info{Name: "widgets", Language: LanguageGo, Framework: "Cobra", Manifest: "go.mod"},
+		},
+		{
+			name: "typescript react",
+			files: map[string]string{
+				"package.json":  `{"name": "web-app", "dependencies": {"react": "^18.0.0"}}`,
+				"tsconfig.json": "{}",
+			},
+			want: Info{Name: "web-app", Language: LanguageTypeScript, Framework: "React", Manifest: "package.json"},
+		},
+		{
+			name:  "python pep 621",
+			files: map[string]string{"pyproject.toml": "[project]\nname = \"billing\"\ndependencies = [\n  \"Django>=4.2\",\n  \"requests\",\n]\n"},
+			want:  Info{Name: "billing", Language: LanguagePython, Framework: "Django", Manifest: "pyproject.toml"},
+		},
+		{
+			name:  "python poetry",
+			files: map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"api\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\nfastapi = \"^0.100\"\n"},
+			want:  Info{Name: "api", Language: LanguagePython, Framework: "FastAPI", Manifest: "pyproject.toml"},
+		},
+		{
+			name:  "rust crate",
+			files: map[string]string{"Cargo.toml": "[package]\nname = \"server\"\n\n[dependencies]\naxum = \"0.7\"\n"},
+			want:  Info{Name: "server", Language: LanguageRust, Framework: "Axum", Manifest: "Cargo.toml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := Detect(dir); got != tt.want {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect_NoManifest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "notes")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := Detect(dir); got != (Info{Name: "notes"}) {
+		t.Errorf("Detect() = %+v, want name from directory only", got)
+	}
+}
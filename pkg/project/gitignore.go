@@ -0,0 +1,113 @@
+package project
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignorePattern is a single parsed .gitignore rule
+type ignorePattern struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	anyDepth bool // Started with **/, so it may match below the root
+}
+
+// Ignorer matches paths against the rules of a root .gitignore file
+type Ignorer struct {
+	patterns []ignorePattern
+}
+
+// LoadIgnorer reads the .gitignore file in dir. A missing file yields an
+// Ignorer that only excludes the .git directory.
+func LoadIgnorer(dir string) *Ignorer {
+	ig := &Ignorer{}
+
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return ig
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ig.Add(scanner.Text())
+	}
+
+	return ig
+}
+
+// Add parses and appends a single .gitignore line
+func (ig *Ignorer) Add(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	p := ignorePattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.HasPrefix(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		if strings.HasPrefix(line, "**/") {
+			p.anyDepth = true
+			line = strings.TrimPrefix(line, "**/")
+		}
+		// Patterns with an inner slash are relative to the .gitignore location
+		p.anchored = strings.Contains(line, "/")
+	}
+	p.pattern = line
+
+	ig.patterns = append(ig.patterns, p)
+}
+
+// Ignored reports whether a slash-separated path relative to the project
+// root is excluded. Later rules override earlier ones, as in git.
+func (ig *Ignorer) Ignored(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return true
+	}
+
+	ignored := false
+	for _, p := range ig.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.matches(rel) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the pattern matches the path
+func (p ignorePattern) matches(rel string) bool {
+	if p.anchored {
+		for {
+			if ok, _ := path.Match(p.pattern, rel); ok {
+				return true
+			}
+			// **/a/b matches a/b in any directory
+			i := strings.Index(rel, "/")
+			if !p.anyDepth || i < 0 {
+				return false
+			}
+			rel = rel[i+1:]
+		}
+	}
+	ok, _ := path.Match(p.pattern, path.Base(rel))
+	return ok
+}
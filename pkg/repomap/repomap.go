@@ -0,0 +1,369 @@
+package repomap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Map is a compact symbol outline of a Go module
+type Map struct {
+	Module   string
+	Packages []*Package
+}
+
+// Package describes the exported API of a single Go package
+type Package struct {
+	Name       string  // Package name from the package clause
+	ImportPath string  // Module path joined with the package directory
+	Dir        string  // Slash-separated directory relative to the module root
+	Types      []*Type // Exported types, sorted by name
+	Funcs      []string
+}
+
+// Type describes an exported type declaration and its method set
+type Type struct {
+	Name       string
+	Kind       string   // "struct", "interface", "func", "= T" for aliases, or the underlying type
+	Methods    []string // Exported method signatures, or interface methods
+	Implements []string // Interfaces in the module this type satisfies
+}
+
+// Build parses the Go module rooted at dir and returns its symbol map.
+// Test files, vendor, testdata, hidden directories and nested modules are
+// skipped, as the go tool does.
+func Build(dir string) (*Map, error) {
+	module, err := modulePath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Map{Module: module}
+	fset := token.NewFileSet()
+	packages := make(map[string]*pkgBuilder)
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p == dir {
+				return nil
+			}
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			// Nested modules are mapped separately
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			// Skip files that do not parse rather than failing the whole map
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		pb, ok := packages[rel]
+		if !ok {
+			pb = newPkgBuilder(module, rel, file.Name.Name)
+			packages[rel] = pb
+		}
+		pb.addFile(fset, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk module: %w", err)
+	}
+
+	for _, pb := range packages {
+		m.Packages = append(m.Packages, pb.build())
+	}
+	sort.Slice(m.Packages, func(i, j int) bool {
+		return m.Packages[i].ImportPath < m.Packages[j].ImportPath
+	})
+
+	m.resolveImplementations(packages)
+
+	return m, nil
+}
+
+// String renders the map as a compact markdown outline
+func (m *Map) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n", m.Module)
+
+	for _, pkg := range m.Packages {
+		if len(pkg.Types) == 0 && len(pkg.Funcs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n%s (package %s)\n", pkg.ImportPath, pkg.Name)
+		for _, t := range pkg.Types {
+			fmt.Fprintf(&b, "  type %s %s\n", t.Name, t.Kind)
+			for _, method := range t.Methods {
+				fmt.Fprintf(&b, "    %s\n", method)
+			}
+			if len(t.Implements) > 0 {
+				fmt.Fprintf(&b, "    implements: %s\n", strings.Join(t.Implements, ", "))
+			}
+		}
+		for _, fn := range pkg.Funcs {
+			fmt.Fprintf(&b, "  %s\n", fn)
+		}
+	}
+
+	return b.String()
+}
+
+// resolveImplementations records which types satisfy which interfaces.
+// Matching is by method name, since the map is built without type checking.
+func (m *Map) resolveImplementations(packages map[string]*pkgBuilder) {
+	type iface struct {
+		name    string
+		methods []string
+	}
+
+	var ifaces []iface
+	for _, pkg := range m.Packages {
+		pb := packages[pkg.Dir]
+		for _, t := range pkg.Types {
+			if t.Kind != "interface" || len(pb.ifaceMethods[t.Name]) == 0 {
+				continue
+			}
+			ifaces = append(ifaces, iface{
+				name:    qualify(pkg, t.Name),
+				methods: pb.ifaceMethods[t.Name],
+			})
+		}
+	}
+
+	for _, pkg := range m.Packages {
+		pb := packages[pkg.Dir]
+		for _, t := range pkg.Types {
+			if t.Kind == "interface" {
+				continue
+			}
+			methods := pb.methodNames[t.Name]
+			for _, in := range ifaces {
+				if satisfies(methods, in.methods) {
+					t.Implements = append(t.Implements, in.name)
+				}
+			}
+		}
+	}
+}
+
+// qualify returns a type name qualified by its package name
+func qualify(pkg *Package, name string) string {
+	return pkg.Name + "." + name
+}
+
+// satisfies reports whether a method name set covers all required methods
+func satisfies(methods map[string]bool, required []string) bool {
+	for _, name := range required {
+		if !methods[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// pkgBuilder accumulates declarations across the files of a package
+type pkgBuilder struct {
+	pkg          *Package
+	types        map[string]*Type
+	methodNames  map[string]map[string]bool // receiver type -> method names
+	ifaceMethods map[string][]string        // interface type -> method names
+}
+
+func newPkgBuilder(module, rel, name string) *pkgBuilder {
+	importPath := module
+	if rel != "." {
+		importPath = path.Join(module, rel)
+	}
+	return &pkgBuilder{
+		pkg: &Package{
+			Name:       name,
+			ImportPath: importPath,
+			Dir:        rel,
+		},
+		types:        make(map[string]*Type),
+		methodNames:  make(map[string]map[string]bool),
+		ifaceMethods: make(map[string][]string),
+	}
+}
+
+// addFile records the exported declarations of a parsed file
+func (pb *pkgBuilder) addFile(fset *token.FileSet, file *ast.File) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				pb.addType(fset, ts)
+			}
+		case *ast.FuncDecl:
+			pb.addFunc(fset, d)
+		}
+	}
+}
+
+// addType records an exported type and, for interfaces, its method names
+func (pb *pkgBuilder) addType(fset *token.FileSet, ts *ast.TypeSpec) {
+	if !ts.Name.IsExported() {
+		return
+	}
+
+	t := pb.typeFor(ts.Name.Name)
+	switch typ := ts.Type.(type) {
+	case *ast.StructType:
+		t.Kind = "struct"
+	case *ast.InterfaceType:
+		t.Kind = "interface"
+		for _, field := range typ.Methods.List {
+			ft, ok := field.Type.(*ast.FuncType)
+			if !ok {
+				// Embedded interfaces are listed but not expanded
+				t.Methods = append(t.Methods, render(fset, field.Type))
+				continue
+			}
+			for _, name := range field.Names {
+				pb.ifaceMethods[t.Name] = append(pb.ifaceMethods[t.Name], name.Name)
+				t.Methods = append(t.Methods, name.Name+strings.TrimPrefix(render(fset, ft), "func"))
+			}
+		}
+	case *ast.FuncType:
+		t.Kind = "func"
+	default:
+		t.Kind = render(fset, ts.Type)
+	}
+	if ts.Assign.IsValid() {
+		t.Kind = "= " + render(fset, ts.Type)
+	}
+}
+
+// addFunc records an exported function or method
+func (pb *pkgBuilder) addFunc(fset *token.FileSet, fn *ast.FuncDecl) {
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		recv := receiverName(fn.Recv.List[0].Type)
+		if pb.methodNames[recv] == nil {
+			pb.methodNames[recv] = make(map[string]bool)
+		}
+		pb.methodNames[recv][fn.Name.Name] = true
+
+		if !fn.Name.IsExported() || !ast.IsExported(recv) {
+			return
+		}
+		t := pb.typeFor(recv)
+		t.Methods = append(t.Methods, signature(fset, fn))
+		return
+	}
+
+	if fn.Name.IsExported() {
+		pb.pkg.Funcs = append(pb.pkg.Funcs, signature(fset, fn))
+	}
+}
+
+// typeFor returns the type entry for name, creating it if needed
+func (pb *pkgBuilder) typeFor(name string) *Type {
+	t, ok := pb.types[name]
+	if !ok {
+		t = &Type{Name: name}
+		pb.types[name] = t
+	}
+	return t
+}
+
+// build finalises the package with sorted declarations
+func (pb *pkgBuilder) build() *Package {
+	for _, t := range pb.types {
+		if t.Kind == "" {
+			// Methods on a type declared in a skipped or unparsable file
+			t.Kind = "(declared elsewhere)"
+		}
+		pb.pkg.Types = append(pb.pkg.Types, t)
+	}
+	sort.Slice(pb.pkg.Types, func(i, j int) bool {
+		return pb.pkg.Types[i].Name < pb.pkg.Types[j].Name
+	})
+	sort.Strings(pb.pkg.Funcs)
+	return pb.pkg
+}
+
+// receiverName extracts the base type name of a method receiver
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	default:
+		return ""
+	}
+}
+
+// signature renders a function declaration without its body or doc comment
+func signature(fset *token.FileSet, fn *ast.FuncDecl) string {
+	decl := *fn
+	decl.Body = nil
+	decl.Doc = nil
+	return render(fset, &decl)
+}
+
+// render prints an AST node as Go source on a single line
+func render(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// modulePath reads the module path from the go.mod file in dir
+func modulePath(dir string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("not a Go module: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+		}
+	}
+
+	return "", fmt.Errorf("no module directive in %s", filepath.Join(dir, "go.mod"))
+}
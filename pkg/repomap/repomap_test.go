@@ -0,0 +1,100 @@
+package repomap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example.com/shop\n\ngo 1.21\n")
+	writeFile(t, dir, "store/store.go", `package store
+
+// Store persists orders
+type Store interface {
+	Save(id string) error
+	Load(id string) (string, error)
+}
+
+type MemoryStore struct{ data map[string]string }
+
+func NewMemoryStore() *MemoryStore { return &MemoryStore{} }
+
+func (m *MemoryStore) Save(id string) error { return nil }
+
+func (m *MemoryStore) Load(id string) (string, error) { return "", nil }
+
+func (m *MemoryStore) reset() {}
+
+type ReadOnly struct{}
+
+func (ReadOnly) Load(id string) (string, error) { return "", nil }
+
+type ID = string
+
+func helper() {}
+`)
+	writeFile(t, dir, "store/store_test.go", "package store\n\nfunc TestOnly() {}\n")
+	writeFile(t, dir, "vendor/dep/dep.go", "package dep\n\nfunc Vendored() {}\n")
+
+	m, err := Build(dir)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if m.Module != "example.com/shop" {
+		t.Errorf("Build() module = %q, want example.com/shop", m.Module)
+	}
+	if len(m.Packages) != 1 || m.Packages[0].ImportPath != "example.com/shop/store" {
+		t.Fatalf("Build() packages = %+v, want only example.com/shop/store", m.Packages)
+	}
+
+	types := make(map[string]*Type)
+	for _, typ := range m.Packages[0].Types {
+		types[typ.Name] = typ
+	}
+
+	if got := types["Store"]; got == nil || got.Kind != "interface" || len(got.Methods) != 2 {
+		t.Errorf("Store = %+v, want interface with 2 methods", got)
+	}
+	if got := types["MemoryStore"]; got == nil || len(got.Methods) != 2 || strings.Join(got.Implements, ",") != "store.Store" {
+		t.Errorf("MemoryStore = %+v, want 2 exported methods implementing store.Store", got)
+	}
+	if got := types["ReadOnly"]; got == nil || len(got.Implements) != 0 {
+		t.Errorf("ReadOnly = %+v, want no implementations", got)
+	}
+	if got := types["ID"]; got == nil || got.Kind != "= string" {
+		t.Errorf("ID = %+v, want alias of string", got)
+	}
+
+	out := m.String()
+	for _, want := range []string{"func NewMemoryStore() *MemoryStore", "func (m *MemoryStore) Save(id string) error", "implements: store.Store"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Map.String() missing %q in:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"helper", "reset", "TestOnly", "Vendored"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Map.String() contains %q:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestBuild_NotAModule(t *testing.T) {
+	if _, err := Build(t.TempDir()); err == nil {
+		t.Errorf("Build() without go.mod error = nil, want error")
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,6 +3,8 @@ package resources
 import (
 	"embed"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -11,12 +13,24 @@ var agentFS embed.FS
 
 // AgentResource represents an agent definition
 type AgentResource struct {
-	Name        string
-	Description string
-	Content     string
-	Mode        string // "primary" | "subagent"
-	Temperature float64
-	Tools       map[string]bool
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Content     string          `json:"content"`
+	Mode        string          `json:"mode"`            // "primary" | "subagent"
+	Model       string          `json:"model,omitempty"` // "provider/model", empty for the installer default
+	Temperature float64         `json:"temperature"`
+	Tools       map[string]bool `json:"tools"`
+
+	// ProjectContext lists the project context sources injected into the prompt
+	ProjectContext []string `json:"project_context,omitempty"`
+	// ContextBudget caps the estimated tokens of injected project context
+	ContextBudget int `json:"context_budget,omitempty"`
+	// Extends names the agent this one inherits from, before resolution
+	Extends string `json:"extends,omitempty"`
+	// Sections maps inherited section headings to "append", "replace" or "remove"
+	Sections map[string]string `json:"sections,omitempty"`
+	// Extra holds frontmatter keys without a dedicated field
+	Extra map[string]interface{} `json:"extra,omitempty"`
 }
 
 // GetAvailableAgents returns all available agents from embedded filesystem
@@ -29,7 +43,7 @@ func GetAvailableAgents() ([]AgentResource, error) {
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+		if !isAgentFile(entry.Name(), entry.IsDir()) {
 			continue
 		}
 
@@ -38,13 +52,75 @@ func GetAvailableAgents() ([]AgentResource, error) {
 			continue
 		}
 
-		agent := parseAgentContent(entry.Name(), string(content))
+		agent, err := ParseAgent(strings.TrimSuffix(entry.Name(), ".md"), string(content))
+		if err != nil {
+			return nil, fmt.Errorf("embedded agent %s: %w", entry.Name(), err)
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, nil
+}
+
+// LoadAgentsFromDir parses every agent file in a directory. A missing
+// directory yields no agents.
+func LoadAgentsFromDir(dir string) ([]AgentResource, error) {
+	var agents []AgentResource
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return agents, nil
+		}
+		return nil, fmt.Errorf("failed to read agent directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !isAgentFile(entry.Name(), entry.IsDir()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent file: %w", err)
+		}
+
+		agent, err := ParseAgent(strings.TrimSuffix(entry.Name(), ".md"), string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
 		agents = append(agents, agent)
 	}
 
 	return agents, nil
 }
 
+// AgentNamesInDir returns the names of the agent files in a directory
+// without parsing them. A missing directory yields no names.
+func AgentNamesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read agent directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if isAgentFile(entry.Name(), entry.IsDir()) {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".md"))
+		}
+	}
+	return names, nil
+}
+
+// isAgentFile reports whether a directory entry is an agent definition
+func isAgentFile(name string, isDir bool) bool {
+	return !isDir && strings.HasSuffix(name, ".md") && name != "README.md"
+}
+
 // GetAgent returns a specific agent by name
 func GetAgent(name string) (AgentResource, error) {
 	agents, err := GetAvailableAgents()
@@ -61,26 +137,6 @@ func GetAgent(name string) (AgentResource, error) {
 	return AgentResource{}, fmt.Errorf("agent %s not found", name)
 }
 
-// parseAgentContent parses agent markdown content to extract metadata
-func parseAgentContent(filename, content string) AgentResource {
-	name := strings.TrimSuffix(filename, ".md")
-
-	// Extract description from Role section
-	description := extractDescription(content)
-
-	// Determine agent type and default settings
-	mode, temperature, tools := getAgentDefaults(name)
-
-	return AgentResource{
-		Name:        name,
-		Description: description,
-		Content:     content,
-		Mode:        mode,
-		Temperature: temperature,
-		Tools:       tools,
-	}
-}
-
 // extractDescription extracts description from Role section
 func extractDescription(content string) string {
 	lines := strings.Split(content, "\n")
@@ -101,84 +157,8 @@ func extractDescription(content string) string {
 	}
 
 	if len(description) > 0 {
-		result := strings.Join(description, " ")
-		if len(result) > 100 {
-			return result[:97] + "..."
-		}
-		return result
+		return strings.Join(description, " ")
 	}
 
 	return "Agent for software development tasks"
 }
-
-// getAgentDefaults returns default configuration for known agents
-func getAgentDefaults(name string) (mode string, temperature float64, tools map[string]bool) {
-	tools = make(map[string]bool)
-
-	switch name {
-	case "implementer":
-		mode = "primary"
-		temperature = 0.3
-		tools["write"] = true
-		tools["edit"] = true
-		tools["bash"] = true
-		tools["read"] = true
-	case "architect":
-		mode = "primary"
-		temperature = 0.2
-		tools["write"] = false
-		tools["edit"] = false
-		tools["bash"] = false
-		tools["read"] = true
-		tools["webfetch"] = true
-	case "tester":
-		mode = "subagent"
-		temperature = 0.2
-		tools["write"] = true
-		tools["edit"] = true
-		tools["bash"] = true
-		tools["read"] = true
-	case "debugger":
-		mode = "subagent"
-		temperature = 0.1
-		tools["write"] = true
-		tools["edit"] = true
-		tools["bash"] = true
-		tools["read"] = true
-	case "reviewer":
-		mode = "subagent"
-		temperature = 0.1
-		tools["write"] = false
-		tools["edit"] = false
-		tools["bash"] = false
-		tools["read"] = true
-	case "refactorer":
-		mode = "subagent"
-		temperature = 0.2
-		tools["write"] = true
-		tools["edit"] = true
-		tools["bash"] = false
-		tools["read"] = true
-	case "documenter":
-		mode = "subagent"
-		temperature = 0.3
-		tools["write"] = true
-		tools["edit"] = true
-		tools["bash"] = false
-		tools["read"] = true
-	case "researcher":
-		mode = "subagent"
-		temperature = 0.4
-		tools["write"] = false
-		tools["edit"] = false
-		tools["bash"] = false
-		tools["read"] = true
-		tools["webfetch"] = true
-	default:
-		mode = "subagent"
-		temperature = 0.3
-		tools["read"] = true
-	}
-
-	return mode, temperature, tools
-}
@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetAvailableAgents_Frontmatter(t *testing.T) {
+	agents, err := GetAvailableAgents()
+	if err != nil {
+		t.Fatalf("GetAvailableAgents() error = %v", err)
+	}
+
+	if len(agents) != 8 {
+		t.Errorf("GetAvailableAgents() = %d agents, want 8", len(agents))
+	}
+
+	for _, agent := range agents {
+		t.Run(agent.Name, func(t *testing.T) {
+			if agent.Name == "README" {
+				t.Fatalf("README.md listed as an agent")
+			}
+			if agent.Description == "" || agent.Mode == "" || len(agent.Tools) == 0 {
+				t.Errorf("agent metadata incomplete: %+v", agent)
+			}
+			if strings.HasPrefix(agent.Content, "---") {
+				t.Errorf("agent content still contains frontmatter")
+			}
+		})
+	}
+}
+
+func TestGetAgent_Metadata(t *testing.T) {
+	agent, err := GetAgent("architect")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+
+	if agent.Mode != "primary" || agent.Temperature != 0.2 {
+		t.Errorf("architect mode/temperature = %s/%.1f, want primary/0.2", agent.Mode, agent.Temperature)
+	}
+	if agent.Tools["write"] || !agent.Tools["webfetch"] {
+		t.Errorf("architect tools = %v, want write disabled and webfetch enabled", agent.Tools)
+	}
+	if strings.Join(agent.ProjectContext, ",") != "repomap" {
+		t.Errorf("architect project context = %v, want [repomap]", agent.ProjectContext)
+	}
+}
+
+func TestLoadAgentsFromDir_CustomAgent(t *testing.T) {
+	dir := t.TempDir()
+	content := "# Go Implementer\n\n## Role\nImplements Go code following the team's conventions for errors, logging and tests across all services.\n"
+	if err := os.WriteFile(filepath.Join(dir, "go-implementer.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, err := LoadAgentsFromDir(dir)
+	if err != nil {
+		t.Fatalf("LoadAgentsFromDir() error = %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("LoadAgentsFromDir() = %d agents, want 1", len(agents))
+	}
+
+	agent := agents[0]
+	if agent.Name != "go-implementer" || agent.Mode != DefaultMode || agent.Temperature != DefaultTemperature || !agent.Tools["read"] {
+		t.Errorf("custom agent defaults = %+v", agent)
+	}
+	if !strings.HasSuffix(agent.Description, "across all services.") {
+		t.Errorf("custom agent description truncated: %q", agent.Description)
+	}
+}
+
+func TestLoadAgentsFromDir_Missing(t *testing.T) {
+	agents, err := LoadAgentsFromDir(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(agents) != 0 {
+		t.Errorf("LoadAgentsFromDir() = %v, %v, want no agents and no error", agents, err)
+	}
+}
@@ -0,0 +1,118 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/frontmatter"
+)
+
+// Defaults applied to agent files that do not specify them
+const (
+	DefaultMode        = ModeSubagent
+	DefaultTemperature = 0.3
+)
+
+// Agent modes understood by the engine
+const (
+	ModePrimary  = "primary"
+	ModeSubagent = "subagent"
+)
+
+// KnownTools lists the tool names OpenCode understands in an agent's tools map
+var KnownTools = []string{
+	"bash", "edit", "glob", "grep", "list", "patch", "read",
+	"todoread", "todowrite", "webfetch", "write",
+}
+
+// IsValidMode reports whether the engine can execute agents of this mode
+func IsValidMode(mode string) bool {
+	return mode == ModePrimary || mode == ModeSubagent
+}
+
+// IsKnownTool reports whether name is a known tool. Names containing a
+// wildcard, such as "mymcp_*", match MCP server tools and are accepted.
+func IsKnownTool(name string) bool {
+	if strings.Contains(name, "*") {
+		return true
+	}
+	for _, tool := range KnownTools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTools returns the tools enabled for agents that do not list any
+func DefaultTools() map[string]bool {
+	return map[string]bool{"read": true}
+}
+
+// ParseAgent parses an agent markdown file with optional YAML frontmatter.
+// Errors report the offending line of the file.
+func ParseAgent(name, content string) (AgentResource, error) {
+	def, err := ParseDefinition(name, content)
+	if err != nil {
+		return AgentResource{}, err
+	}
+
+	return def.Agent(), nil
+}
+
+// FromFrontmatter builds an agent from parsed frontmatter and body,
+// applying defaults for fields the frontmatter leaves unset
+func FromFrontmatter(name string, fm frontmatter.Frontmatter, body string) AgentResource {
+	agent := AgentResource{
+		Name:           name,
+		Description:    fm.Description,
+		Content:        body,
+		Mode:           fm.Mode,
+		Model:          fm.Model,
+		Temperature:    DefaultTemperature,
+		Tools:          make(map[string]bool),
+		ProjectContext: fm.ProjectContext,
+		ContextBudget:  fm.ContextBudget,
+		Extends:        fm.Extends,
+		Sections:       fm.Sections,
+		Extra:          fm.Extra,
+	}
+
+	if agent.Description == "" {
+		agent.Description = extractDescription(body)
+	}
+	if agent.Mode == "" {
+		agent.Mode = DefaultMode
+	}
+	if fm.Temperature != nil {
+		agent.Temperature = *fm.Temperature
+	}
+	if fm.Tools == nil {
+		agent.Tools = DefaultTools()
+	}
+	for tool, enabled := range fm.Tools {
+		agent.Tools[tool] = enabled
+	}
+
+	return agent
+}
+
+// Frontmatter returns the frontmatter fields describing the agent
+func (a AgentResource) Frontmatter() frontmatter.Frontmatter {
+	tools := make(map[string]bool, len(a.Tools))
+	for tool, enabled := range a.Tools {
+		tools[tool] = enabled
+	}
+
+	return frontmatter.Frontmatter{
+		Description:    a.Description,
+		Mode:           a.Mode,
+		Model:          a.Model,
+		Temperature:    frontmatter.Float(a.Temperature),
+		ProjectContext: a.ProjectContext,
+		ContextBudget:  a.ContextBudget,
+		Tools:          tools,
+		Extends:        a.Extends,
+		Sections:       a.Sections,
+		Extra:          a.Extra,
+	}
+}
@@ -0,0 +1,206 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/frontmatter"
+)
+
+// Section override modes for agents that extend another agent
+const (
+	SectionAppend  = "append"
+	SectionReplace = "replace"
+	SectionRemove  = "remove"
+)
+
+// IsValidSectionMode reports whether mode is a known section override mode
+func IsValidSectionMode(mode string) bool {
+	return mode == SectionAppend || mode == SectionReplace || mode == SectionRemove
+}
+
+// Definition is an agent file as written, before inheritance is resolved.
+// Unlike AgentResource, it distinguishes fields that are unset from fields
+// set to their zero value.
+type Definition struct {
+	Name        string
+	Frontmatter frontmatter.Frontmatter
+	Body        string
+}
+
+// ParseDefinition parses an agent markdown file without resolving inheritance
+func ParseDefinition(name, content string) (Definition, error) {
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	return Definition{
+		Name:        name,
+		Frontmatter: doc.Frontmatter,
+		Body:        doc.Body,
+	}, nil
+}
+
+// Agent converts the definition to an agent, applying defaults
+func (d Definition) Agent() AgentResource {
+	return FromFrontmatter(d.Name, d.Frontmatter, d.Body)
+}
+
+// GetDefinition returns the definition of an embedded agent
+func GetDefinition(name string) (Definition, error) {
+	content, err := agentFS.ReadFile("embed/agents/" + name + ".md")
+	if err != nil || !isAgentFile(name+".md", false) {
+		return Definition{}, fmt.Errorf("agent %s not found", name)
+	}
+	return ParseDefinition(name, string(content))
+}
+
+// Merge overlays child onto parent. Frontmatter fields set in the child
+// override the parent's, tools are merged key by key, and body sections
+// replace, append to or remove the parent's sections of the same heading
+// according to the child's sections map. New sections are appended.
+func Merge(parent, child Definition) (Definition, error) {
+	for heading, mode := range child.Frontmatter.Sections {
+		if !IsValidSectionMode(mode) {
+			return Definition{}, fmt.Errorf("agent %s: invalid mode %q for section %q", child.Name, mode, heading)
+		}
+	}
+
+	return Definition{
+		Name:        child.Name,
+		Frontmatter: mergeFrontmatter(parent.Frontmatter, child.Frontmatter),
+		Body:        mergeBody(parent.Body, child.Body, child.Frontmatter.Sections),
+	}, nil
+}
+
+// mergeFrontmatter overlays fields set in child onto parent
+func mergeFrontmatter(parent, child frontmatter.Frontmatter) frontmatter.Frontmatter {
+	merged := parent
+
+	if child.Description != "" {
+		merged.Description = child.Description
+	}
+	if child.Mode != "" {
+		merged.Mode = child.Mode
+	}
+	if child.Model != "" {
+		merged.Model = child.Model
+	}
+	if child.Temperature != nil {
+		merged.Temperature = child.Temperature
+	}
+	if child.ProjectContext != nil {
+		merged.ProjectContext = child.ProjectContext
+	}
+	if child.ContextBudget != 0 {
+		merged.ContextBudget = child.ContextBudget
+	}
+
+	if child.Tools != nil {
+		merged.Tools = make(map[string]bool, len(parent.Tools)+len(child.Tools))
+		for tool, enabled := range parent.Tools {
+			merged.Tools[tool] = enabled
+		}
+		for tool, enabled := range child.Tools {
+			merged.Tools[tool] = enabled
+		}
+	}
+
+	if child.Extra != nil {
+		merged.Extra = make(map[string]interface{}, len(parent.Extra)+len(child.Extra))
+		for key, value := range parent.Extra {
+			merged.Extra[key] = value
+		}
+		for key, value := range child.Extra {
+			merged.Extra[key] = value
+		}
+	}
+
+	// Inheritance directives are consumed by the merge
+	merged.Extends = parent.Extends
+	merged.Sections = nil
+	merged.Override = false
+
+	return merged
+}
+
+// section is a "## " heading and the lines that follow it
+type section struct {
+	heading string
+	content string // Full text including the heading line
+}
+
+// splitSections splits a markdown body into its preamble and "## " sections.
+// Headings inside fenced code blocks belong to the enclosing section.
+func splitSections(body string) (string, []section) {
+	var preamble strings.Builder
+	var sections []section
+	inFence := false
+
+	for _, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "## ") {
+			sections = append(sections, section{heading: strings.TrimSpace(strings.TrimPrefix(line, "## "))})
+		}
+		if len(sections) == 0 {
+			preamble.WriteString(line)
+		} else {
+			sections[len(sections)-1].content += line
+		}
+	}
+
+	return preamble.String(), sections
+}
+
+// mergeBody combines parent and child markdown by section heading.
+// Sections in the result are separated by a single blank line.
+func mergeBody(parentBody, childBody string, modes map[string]string) string {
+	parentPreamble, parentSections := splitSections(parentBody)
+	childPreamble, childSections := splitSections(childBody)
+
+	preamble := parentPreamble
+	if strings.TrimSpace(childPreamble) != "" {
+		preamble = childPreamble
+	}
+
+	childByHeading := make(map[string]section)
+	for _, s := range childSections {
+		childByHeading[s.heading] = s
+	}
+
+	var blocks []string
+	if text := strings.TrimSpace(preamble); text != "" {
+		blocks = append(blocks, text)
+	}
+
+	inherited := make(map[string]bool)
+	for _, s := range parentSections {
+		inherited[s.heading] = true
+		override, overridden := childByHeading[s.heading]
+
+		switch mode := modes[s.heading]; {
+		case mode == SectionRemove:
+			continue
+		case overridden && mode == SectionAppend:
+			_, extra, _ := strings.Cut(override.content, "\n")
+			blocks = append(blocks, strings.TrimRight(s.content, "\n")+"\n"+strings.Trim(extra, "\n"))
+		case overridden:
+			blocks = append(blocks, strings.TrimRight(override.content, "\n"))
+		default:
+			blocks = append(blocks, strings.TrimRight(s.content, "\n"))
+		}
+	}
+
+	// Sections the parent does not have are added at the end
+	for _, s := range childSections {
+		if !inherited[s.heading] && modes[s.heading] != SectionRemove {
+			blocks = append(blocks, strings.TrimRight(s.content, "\n"))
+		}
+	}
+
+	return strings.Join(blocks, "\n\n") + "\n"
+}
@@ -0,0 +1,129 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+)
+
+const parentAgent = `---
+description: Implements features
+mode: subagent
+temperature: 0.3
+tools:
+  read: true
+  write: true
+---
+
+# Implementer
+
+## Role
+Implements features.
+
+## Guidelines
+- Keep changes small
+
+` + "```markdown\n## Not a heading\n```" + `
+
+## Output Deliverables
+- Working code
+`
+
+func mustDefinition(t *testing.T, name, content string) Definition {
+	t.Helper()
+	def, err := ParseDefinition(name, content)
+	if err != nil {
+		t.Fatalf("ParseDefinition(%s) error = %v", name, err)
+	}
+	return def
+}
+
+func TestMerge_Frontmatter(t *testing.T) {
+	parent := mustDefinition(t, "implementer", parentAgent)
+	child := mustDefinition(t, "go-implementer", "---\nextends: implementer\ntemperature: 0.1\ntools:\n  bash: true\n  write: false\n---\n")
+
+	merged, err := Merge(parent, child)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	agent := merged.Agent()
+	if agent.Name != "go-implementer" || agent.Description != "Implements features" || agent.Temperature != 0.1 {
+		t.Errorf("merged agent = %s %q %.1f, want go-implementer \"Implements features\" 0.1", agent.Name, agent.Description, agent.Temperature)
+	}
+	if !agent.Tools["read"] || !agent.Tools["bash"] || agent.Tools["write"] {
+		t.Errorf("merged tools = %v, want read and bash enabled, write disabled", agent.Tools)
+	}
+	if agent.Extends != "" || agent.Sections != nil {
+		t.Errorf("merged inheritance directives = %q %v, want none", agent.Extends, agent.Sections)
+	}
+	if parent.Frontmatter.Tools["bash"] {
+		t.Errorf("Merge() modified parent tools")
+	}
+}
+
+func TestMerge_Sections(t *testing.T) {
+	parent := mustDefinition(t, "implementer", parentAgent)
+
+	tests := []struct {
+		name    string
+		child   string
+		want    []string
+		wantNot []string
+	}{
+		{
+			name:  "inherit body",
+			child: "---\nextends: implementer\n---\n",
+			want:  []string{"# Implementer", "## Role\nImplements features.", "## Not a heading", "## Output Deliverables"},
+		},
+		{
+			name:    "replace by default",
+			child:   "---\nextends: implementer\n---\n\n## Role\nImplements Go code.\n",
+			want:    []string{"## Role\nImplements Go code.\n\n## Guidelines"},
+			wantNot: []string{"Implements features."},
+		},
+		{
+			name:  "append",
+			child: "---\nextends: implementer\nsections:\n  Guidelines: append\n---\n\n## Guidelines\n- Wrap errors with %w\n",
+			want:  []string{"```\n- Wrap errors with %w\n\n## Output Deliverables"},
+		},
+		{
+			name:    "remove",
+			child:   "---\nextends: implementer\nsections:\n  Guidelines: remove\n---\n",
+			want:    []string{"## Role\nImplements features.\n\n## Output Deliverables"},
+			wantNot: []string{"Keep changes small"},
+		},
+		{
+			name:  "new section and preamble",
+			child: "---\nextends: implementer\n---\n\n# Go Implementer\n\n## Testing\n- Table-driven tests\n",
+			want:  []string{"# Go Implementer\n\n## Role", "- Working code\n\n## Testing\n- Table-driven tests\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := Merge(parent, mustDefinition(t, "go-implementer", tt.child))
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(merged.Body, want) {
+					t.Errorf("merged body missing %q:\n%s", want, merged.Body)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(merged.Body, wantNot) {
+					t.Errorf("merged body contains %q:\n%s", wantNot, merged.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestMerge_InvalidSectionMode(t *testing.T) {
+	parent := mustDefinition(t, "implementer", parentAgent)
+	child := mustDefinition(t, "go-implementer", "---\nextends: implementer\nsections:\n  Role: prepend\n---\n")
+
+	if _, err := Merge(parent, child); err == nil {
+		t.Errorf("Merge() error = nil, want invalid mode error")
+	}
+}
@@ -0,0 +1,20 @@
+package resources
+
+import "runtime/debug"
+
+// Version identifies this release of the embedded agent definitions. It is
+// normally set at build time with
+// -ldflags "-X github.com/gsmlg-dev/open-code-agents/pkg/resources.Version=v1.2.3".
+var Version = ""
+
+// BundleVersion returns the version of the embedded agents, falling back to
+// the module version recorded by `go install` and then to "dev"
+func BundleVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
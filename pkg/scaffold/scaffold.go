@@ -0,0 +1,198 @@
+// Package scaffold sets up a project's .opencode directory for the
+// detected language and framework
+package scaffold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/project"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+// DefaultAgents are installed when no agents are chosen: the agents used for
+// everyday changes and by the sample workflows
+var DefaultAgents = []string{"implementer", "reviewer", "tester", "debugger"}
+
+// File actions reported in FileResult
+const (
+	FileCreated     = "created"
+	FileOverwritten = "overwritten"
+	FileSkipped     = "skipped" // Already exists
+)
+
+// Options control what Init writes
+type Options struct {
+	// Agents to install; nil installs DefaultAgents
+	Agents []string
+	// Force overwrites scaffold files that already exist
+	Force bool
+	// OnConflict decides what happens to agent files with local
+	// modifications. If nil, they are kept.
+	OnConflict func(agent string) installer.ConflictPolicy
+}
+
+// FileResult describes what happened to one scaffold file
+type FileResult struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+// Result reports what Init did
+type Result struct {
+	Project project.Info               `json:"project"`
+	Dir     string                     `json:"dir"`
+	Files   []FileResult               `json:"files"`
+	Agents  []*installer.InstallResult `json:"agents"`
+}
+
+// gitignore keeps the artifacts of installs and runs out of version control
+const gitignore = `# Written by opencode-setup; not meant to be committed
+backup-*/
+.staging-*
+sessions/
+config.json.*.bak
+`
+
+// Init creates the .opencode directory of the project in the current
+// directory: config.json with prompt variables for the detected project,
+// a .gitignore for run artifacts, sample workflows and the chosen agents.
+// Existing files are kept unless opts.Force is set, so Init can be re-run.
+func Init(opts Options) (*Result, error) {
+	dir, err := config.GetConfigPath(config.ProjectScope)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Dir(dir)
+
+	names := opts.Agents
+	if names == nil {
+		names = DefaultAgents
+	}
+	var agents []resources.AgentResource
+	for _, name := range names {
+		agent, err := resources.GetAgent(name)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s not found", name)
+		}
+		agents = append(agents, agent)
+	}
+
+	info := project.Detect(root)
+	vars := project.Commands(root, info)
+	result := &Result{Project: info, Dir: dir}
+
+	configData, err := json.MarshalIndent(struct {
+		Version    int               `json:"version"`
+		PromptVars map[string]string `json:"prompt_vars"`
+	}{config.CurrentVersion, vars}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	workflowDir, err := config.GetWorkflowDir(config.ProjectScope)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		path    string
+		content string
+	}{
+		{filepath.Join(dir, "config.json"), string(configData) + "\n"},
+		{filepath.Join(dir, ".gitignore"), gitignore},
+		{filepath.Join(workflowDir, "quick-fix.yaml"), quickFixWorkflow(vars)},
+		{filepath.Join(workflowDir, "review-changes.yaml"), reviewWorkflow(vars)},
+	}
+	for _, f := range files {
+		action, err := writeFile(f.path, f.content, opts.Force)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, FileResult{Path: f.path, Action: action})
+	}
+
+	installOpts := installer.InstallOptions{OnConflict: opts.OnConflict}
+	if installOpts.OnConflict == nil {
+		installOpts.OnConflict = func(string) installer.ConflictPolicy { return installer.ConflictSkip }
+	}
+	result.Agents, err = installer.InstallAgents(agents, config.ProjectScope, installOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// writeFile writes content to path unless the file exists and force is not set
+func writeFile(path, content string, force bool) (string, error) {
+	action := FileCreated
+	if _, err := os.Stat(path); err == nil {
+		if !force {
+			return FileSkipped, nil
+		}
+		action = FileOverwritten
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return action, nil
+}
+
+// workflowHeader explains a sample workflow file
+const workflowHeader = `# Sample workflow created by opencode-setup init. Run it with:
+#   %s
+# {{placeholders}} are filled from --set values and the context defaults
+# below; {{last_output}} is the output of the previous step.
+`
+
+// sampleContext renders the context defaults of the sample workflows: the
+// project's test command, or a description when it has no known command
+func sampleContext(vars map[string]string) string {
+	test := vars["test_command"]
+	if test == "" {
+		test = "the project's test suite"
+	}
+	return "context:\n  test_command: " + strconv.Quote(test) + "\n"
+}
+
+func quickFixWorkflow(vars map[string]string) string {
+	return fmt.Sprintf(workflowHeader, `opencode-setup commands workflow run quick-fix --set bug_description="..."`) + `name: quick-fix
+description: Diagnose a bug, fix it and add a regression test
+` + sampleContext(vars) + `steps:
+  - agent_name: debugger
+    input: "Diagnose the issue: {{bug_description}}"
+    required: true
+  - agent_name: implementer
+    input: "Fix the diagnosed issue: {{last_output}}"
+    required: true
+  - agent_name: tester
+    input: "Add a regression test for the fix and run it with {{test_command}}: {{last_output}}"
+    required: true
+`
+}
+
+func reviewWorkflow(vars map[string]string) string {
+	return fmt.Sprintf(workflowHeader, `git diff | opencode-setup commands workflow run review-changes --set-file changes=-`) + `name: review-changes
+description: Review a change and suggest the tests it is missing
+` + sampleContext(vars) + `steps:
+  - agent_name: reviewer
+    input: "Review these changes: {{changes}}"
+    required: true
+  - agent_name: tester
+    input: "Suggest tests for the gaps found in the review, runnable with {{test_command}}: {{last_output}}"
+    required: false
+`
+}
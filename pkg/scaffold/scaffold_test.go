@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/installer"
+	"github.com/gsmlg-dev/open-code-agents/pkg/orchestrator"
+)
+
+// chdirProject makes an empty temporary directory the current project
+func chdirProject(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestInit(t *testing.T) {
+	dir := chdirProject(t)
+	if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte("[package]\nname = \"server\"\n\n[dependencies]\naxum = \"0.7\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Init(Options{})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if result.Project.Language != "Rust" || result.Project.Framework != "Axum" {
+		t.Errorf("Project = %+v, want Rust/Axum", result.Project)
+	}
+	for _, file := range result.Files {
+		if file.Action != FileCreated {
+			t.Errorf("%s: action = %s, want created", file.Path, file.Action)
+		}
+	}
+
+	cfg, err := config.LoadConfig(config.ProjectScope)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := cfg.PromptVars["test_command"]; got != "cargo test" {
+		t.Errorf("prompt_vars.test_command = %q, want cargo test", got)
+	}
+	if version, err := config.FileVersion(config.ProjectScope); err != nil || version != config.CurrentVersion {
+		t.Errorf("config version = %d, %v, want %d", version, err, config.CurrentVersion)
+	}
+
+	installed, err := config.GetInstalledAgents(config.ProjectScope)
+	if err != nil || len(installed) != len(DefaultAgents) {
+		t.Errorf("installed %d agents (%v), want %d", len(installed), err, len(DefaultAgents))
+	}
+
+	gitignore, err := os.ReadFile(filepath.Join(result.Dir, ".gitignore"))
+	if err != nil || !strings.Contains(string(gitignore), "backup-*/") || !strings.Contains(string(gitignore), ".staging-*") {
+		t.Errorf(".gitignore = %q, %v, want backup and staging patterns", gitignore, err)
+	}
+
+	// The sample workflows load and only need the values they document
+	for _, name := range []string{"quick-fix", "review-changes"} {
+		workflow, err := orchestrator.GetWorkflow(name)
+		if err != nil {
+			t.Fatalf("GetWorkflow(%s) error = %v", name, err)
+		}
+		if workflow.Context["test_command"] != "cargo test" {
+			t.Errorf("%s: test_command = %q, want cargo test", name, workflow.Context["test_command"])
+		}
+		if missing := workflow.MissingContext(nil); len(missing) != 1 {
+			t.Errorf("%s: MissingContext() = %v, want one value to give", name, missing)
+		}
+	}
+}
+
+func TestInit_Rerun(t *testing.T) {
+	chdirProject(t)
+	if _, err := Init(Options{Agents: []string{"tester"}}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	configFile := filepath.Join(".opencode", "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"prompt_vars": {"team": "web"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	agentFile := filepath.Join(".opencode", "agent", "tester.md")
+	if err := os.WriteFile(agentFile, []byte("# Local tester\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Init(Options{Agents: []string{"tester"}})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	for _, file := range result.Files {
+		if file.Action != FileSkipped {
+			t.Errorf("%s: action = %s, want skipped", file.Path, file.Action)
+		}
+	}
+	if result.Agents[0].Action != installer.ActionSkipped {
+		t.Errorf("tester action = %s, want modified file kept", result.Agents[0].Action)
+	}
+
+	if _, err := Init(Options{Agents: []string{"tester"}, Force: true}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if data, _ := os.ReadFile(configFile); strings.Contains(string(data), "team") {
+		t.Errorf("config.json = %s, want it overwritten with --force", data)
+	}
+
+	if _, err := Init(Options{Agents: []string{"nonexistent"}}); err == nil {
+		t.Error("Init() with an unknown agent succeeded, want error")
+	}
+}
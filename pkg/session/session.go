@@ -0,0 +1,201 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+)
+
+// ErrNotFound is returned when a session does not exist in the store
+var ErrNotFound = errors.New("session not found")
+
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message represents a single turn in a conversation
+type Message struct {
+	Role       string    `json:"role"`
+	Content    string    `json:"content"`
+	ToolCallID string    `json:"tool_call_id,omitempty"` // Set on tool results
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// Session represents a persisted multi-turn conversation with one agent
+type Session struct {
+	Name      string                 `json:"name"`
+	AgentName string                 `json:"agent_name"`
+	Messages  []Message              `json:"messages"`
+	Context   map[string]interface{} `json:"context,omitempty"`
+	Created   time.Time              `json:"created"`
+	Updated   time.Time              `json:"updated"`
+}
+
+// New creates an empty session for the given agent
+func New(name, agentName string) *Session {
+	now := time.Now()
+	return &Session{
+		Name:      name,
+		AgentName: agentName,
+		Messages:  []Message{},
+		Context:   make(map[string]interface{}),
+		Created:   now,
+		Updated:   now,
+	}
+}
+
+// Append adds a message to the session history
+func (s *Session) Append(role, content string) {
+	now := time.Now()
+	s.Messages = append(s.Messages, Message{
+		Role:      role,
+		Content:   content,
+		Timestamp: now,
+	})
+	s.Updated = now
+}
+
+// Trim drops the oldest messages so that at most max remain.
+// A max of zero or less disables trimming.
+func (s *Session) Trim(max int) {
+	if max <= 0 || len(s.Messages) <= max {
+		return
+	}
+	s.Messages = append([]Message(nil), s.Messages[len(s.Messages)-max:]...)
+}
+
+// Store persists sessions as JSON files in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a session store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultStore returns the session store for a given scope
+func DefaultStore(scope config.Scope) (*Store, error) {
+	dir, err := config.GetSessionDir(scope)
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(dir), nil
+}
+
+// Load reads a session by name
+func (s *Store) Load(name string) (*Session, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", name, err)
+	}
+
+	if sess.Context == nil {
+		sess.Context = make(map[string]interface{})
+	}
+
+	return &sess, nil
+}
+
+// Save writes a session to the store
+func (s *Store) Save(sess *Session) error {
+	path, err := s.path(sess.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+
+	return nil
+}
+
+// List returns all sessions in the store, most recently updated first
+func (s *Store) List() ([]Session, error) {
+	var sessions []Session
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sessions, nil
+		}
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		sess, err := s.Load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, *sess)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+
+	return sessions, nil
+}
+
+// Delete removes a session from the store
+func (s *Store) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// path returns the file path for a session, rejecting names that escape the store
+func (s *Store) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid session name: %q", name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSession_Trim(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages int
+		max      int
+		want     int
+	}{
+		{"under limit", 3, 5, 3},
+		{"over limit", 10, 4, 4},
+		{"disabled", 10, 0, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := New("test", "implementer")
+			for i := 0; i < tt.messages; i++ {
+				sess.Append(RoleUser, fmt.Sprintf("message %d", i))
+			}
+
+			sess.Trim(tt.max)
+
+			if len(sess.Messages) != tt.want {
+				t.Errorf("Session.Trim() kept %d messages, want %d", len(sess.Messages), tt.want)
+			}
+			last := fmt.Sprintf("message %d", tt.messages-1)
+			if sess.Messages[len(sess.Messages)-1].Content != last {
+				t.Errorf("Session.Trim() dropped newest message, last = %q", sess.Messages[len(sess.Messages)-1].Content)
+			}
+		})
+	}
+}
+
+func TestStore_SaveLoadDelete(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	sess := New("feature", "implementer")
+	sess.Append(RoleUser, "hello")
+	sess.Context["design"] = "completed"
+
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Store.Save() error = %v", err)
+	}
+
+	loaded, err := store.Load("feature")
+	if err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+	if loaded.AgentName != "implementer" || len(loaded.Messages) != 1 {
+		t.Errorf("Store.Load() = %+v, want implementer session with 1 message", loaded)
+	}
+	if loaded.Context["design"] != "completed" {
+		t.Errorf("Store.Load() context = %v, want design=completed", loaded.Context)
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		t.Fatalf("Store.List() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("Store.List() = %d sessions, want 1", len(sessions))
+	}
+
+	if err := store.Delete("feature"); err != nil {
+		t.Fatalf("Store.Delete() error = %v", err)
+	}
+	if _, err := store.Load("feature"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Store.Load() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_InvalidName(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for _, name := range []string{"", "..", "../escape", `a\b`} {
+		if _, err := store.Load(name); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Store.Load(%q) error = %v, want invalid name error", name, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tmpl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gsmlg-dev/open-code-agents/pkg/project"
+)
+
+// maxIncludeDepth limits how deeply includes may nest
+const maxIncludeDepth = 10
+
+// Vars are the values available to agent content templates
+type Vars struct {
+	ProjectName string
+	Language    string
+	Framework   string
+	// Vars holds user-defined variables from the prompt_vars config setting
+	Vars map[string]string
+}
+
+// Includer returns the content of an included file given its
+// slash-separated path relative to an agent directory
+type Includer func(name string) (string, error)
+
+// DirIncluder returns an Includer that looks for files in dirs, in order
+func DirIncluder(dirs ...string) Includer {
+	return func(name string) (string, error) {
+		for _, dir := range dirs {
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to read include %q: %w", name, err)
+			}
+			return string(data), nil
+		}
+		return "", fmt.Errorf("include %q not found in %s", name, strings.Join(dirs, ", "))
+	}
+}
+
+// directive matches the documented directives: {{.ProjectName}},
+// {{.Language}}, {{.Framework}}, {{.Vars.name}} and {{include "path"}}.
+// Any other {{...}}, such as a Helm or Jinja example, is not a directive and
+// is left as written.
+var directive = regexp.MustCompile(`\{\{\s*(?:\.(ProjectName|Language|Framework)|\.Vars\.([A-Za-z_][A-Za-z0-9_]*)|include\s+("(?:[^"\\\n]|\\.)*"))\s*\}\}`)
+
+// HasDirectives reports whether content contains template directives
+func HasDirectives(content string) bool {
+	return directive.MatchString(content)
+}
+
+// Expand resolves {{include "path"}} directives and variables such as
+// {{.ProjectName}} in content. Included files are expanded recursively.
+// Other text, including braces that are not a directive, is kept as is.
+func Expand(content string, vars Vars, include Includer) (string, error) {
+	return expand(content, vars, include, nil)
+}
+
+// ProjectVars returns the variables for agent content used in the project
+// in dir. Detected values can be overridden with the project_name, language
+// and framework prompt variables.
+func ProjectVars(dir string, promptVars map[string]string) Vars {
+	info := project.Detect(dir)
+	vars := Vars{
+		ProjectName: info.Name,
+		Language:    info.Language,
+		Framework:   info.Framework,
+		Vars:        promptVars,
+	}
+
+	if v, ok := promptVars["project_name"]; ok {
+		vars.ProjectName = v
+	}
+	if v, ok := promptVars["language"]; ok {
+		vars.Language = v
+	}
+	if v, ok := promptVars["framework"]; ok {
+		vars.Framework = v
+	}
+
+	return vars
+}
+
+// expand resolves the directives in content; stack holds the includes
+// being expanded
+func expand(content string, vars Vars, include Includer, stack []string) (string, error) {
+	var firstErr error
+	result := directive.ReplaceAllStringFunc(content, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+		text, err := resolve(directive.FindStringSubmatch(match), vars, include, stack)
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		return text
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return result, nil
+}
+
+// resolve returns the text of one directive given its submatches
+func resolve(m []string, vars Vars, include Includer, stack []string) (string, error) {
+	switch {
+	case m[1] == "ProjectName":
+		return vars.ProjectName, nil
+	case m[1] == "Language":
+		return vars.Language, nil
+	case m[1] == "Framework":
+		return vars.Framework, nil
+	case m[2] != "":
+		v, ok := vars.Vars[m[2]]
+		if !ok {
+			return "", fmt.Errorf("prompt variable %q is not set; set it with 'opencode-setup config set prompt_vars.%s <value> --scope project'", m[2], m[2])
+		}
+		return v, nil
+	}
+
+	name, err := strconv.Unquote(m[3])
+	if err != nil {
+		return "", fmt.Errorf("invalid include path %s", m[3])
+	}
+	file, err := cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+	for _, open := range stack {
+		if open == file {
+			return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), file)
+		}
+	}
+	if len(stack) >= maxIncludeDepth {
+		return "", fmt.Errorf("includes nested more than %d deep", maxIncludeDepth)
+	}
+
+	text, err := include(file)
+	if err != nil {
+		return "", err
+	}
+	text, err = expand(text, vars, include, append(stack, file))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	// The directive's own line ending follows the included text
+	return strings.TrimSuffix(text, "\n"), nil
+}
+
+// cleanPath validates an include path, which must stay inside the agent directory
+func cleanPath(name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || cleaned == "." {
+		return "", fmt.Errorf("include path %q must be relative to the agent directory", name)
+	}
+	return cleaned, nil
+}
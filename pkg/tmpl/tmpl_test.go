@@ -0,0 +1,98 @@
+package tmpl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	files := map[string]string{
+		"shared/go-style.md": "## Go Style\n- Wrap errors for {{.ProjectName}}\n",
+		"shared/all.md":      "{{include \"shared/go-style.md\"}}\n{{include \"shared/team.md\"}}\n",
+		"shared/team.md":     "Team: {{.Vars.team}}\n",
+		"shared/loop.md":     "{{include \"shared/loop.md\"}}\n",
+	}
+	include := func(name string) (string, error) {
+		if content, ok := files[name]; ok {
+			return content, nil
+		}
+		return "", fmt.Errorf("include %q not found", name)
+	}
+	vars := Vars{ProjectName: "widgets", Language: "Go", Vars: map[string]string{"team": "platform"}}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{"no directives", "Plain {text}\n", "Plain {text}\n", ""},
+		{"variables", "Project {{.ProjectName}} in {{.Language}}\n", "Project widgets in Go\n", ""},
+		{"include", "# Agent\n\n{{include \"shared/go-style.md\"}}\n", "# Agent\n\n## Go Style\n- Wrap errors for widgets\n", ""},
+		{"nested include", "{{include \"shared/all.md\"}}\n", "## Go Style\n- Wrap errors for widgets\nTeam: platform\n", ""},
+		{"cleaned path", "{{include \"shared/../shared/team.md\"}}", "Team: platform", ""},
+		{"missing variable", "{{.Vars.owner}}", "", "owner"},
+		{"missing include", "{{include \"shared/none.md\"}}", "", "not found"},
+		{"include cycle", "{{include \"shared/loop.md\"}}", "", "include cycle"},
+		{"escaping path", "{{include \"../secrets.md\"}}", "", "must be relative"},
+		{"spaced directives", "{{ .ProjectName }} for {{ .Vars.team }}", "widgets for platform", ""},
+		{"helm example", "Set `{{ .Values.image }}` for {{.ProjectName}}\n", "Set `{{ .Values.image }}` for widgets\n", ""},
+		{"other braces", "{{include}} {{ range .Items }}{{ end }} {{{{raw}}}}", "{{include}} {{ range .Items }}{{ end }} {{{{raw}}}}", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.content, vars, include)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expand() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirIncluder(t *testing.T) {
+	project, user := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(project, "shared/style.md", "project style")
+	write(user, "shared/style.md", "user style")
+	write(user, "shared/user-only.md", "user only")
+
+	include := DirIncluder(project, user)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"shared/style.md", "project style"},
+		{"shared/user-only.md", "user only"},
+	}
+	for _, tt := range tests {
+		if got, err := include(tt.name); err != nil || got != tt.want {
+			t.Errorf("DirIncluder()(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := include("shared/missing.md"); err == nil {
+		t.Errorf("DirIncluder() for a missing file error = nil, want error")
+	}
+}
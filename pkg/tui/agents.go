@@ -0,0 +1,308 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+// Action is what the user chose to do in the agent manager
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionInstall
+	ActionUninstall
+)
+
+// Selection is the outcome of the agent manager
+type Selection struct {
+	Action Action
+	Agents []string
+	Scope  config.Scope
+}
+
+// AgentItem is an agent in the manager with its install status per scope
+type AgentItem struct {
+	Agent  resources.AgentResource
+	Status map[config.Scope]string // config.Status* values; absent if not installed
+}
+
+// RunAgentManager shows the agent manager and returns what the user chose.
+// scope is the scope selected when the manager opens.
+func RunAgentManager(items []AgentItem, scope config.Scope) (Selection, error) {
+	final, err := newProgram(newAgentModel(items, scope)).Run()
+	if err != nil {
+		return Selection{}, fmt.Errorf("failed to run agent manager: %w", err)
+	}
+	return final.(agentModel).selection, nil
+}
+
+// agentModel is the state of the agent manager
+type agentModel struct {
+	items     []AgentItem
+	cursor    int
+	selected  map[string]bool
+	scope     config.Scope
+	offset    int  // First visible line of the detail pane
+	confirm   bool // Waiting for confirmation to uninstall
+	message   string
+	width     int
+	height    int
+	selection Selection
+}
+
+func newAgentModel(items []AgentItem, scope config.Scope) agentModel {
+	return agentModel{
+		items:    items,
+		selected: make(map[string]bool),
+		scope:    scope,
+		width:    defaultWidth,
+		height:   defaultHeight,
+	}
+}
+
+func (m agentModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m agentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		return m.handleKey(msg.String())
+	}
+	return m, nil
+}
+
+// handleKey applies a key press
+func (m agentModel) handleKey(key string) (tea.Model, tea.Cmd) {
+	if m.confirm {
+		m.confirm = false
+		if key == "y" || key == "Y" {
+			m.selection = Selection{Action: ActionUninstall, Agents: m.installedChosen(), Scope: m.scope}
+			return m, tea.Quit
+		}
+		m.message = "Uninstall cancelled"
+		return m, nil
+	}
+
+	m.message = ""
+	switch key {
+	case "ctrl+c", "q", "esc":
+		m.selection = Selection{Action: ActionNone}
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			m.offset = 0
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+			m.offset = 0
+		}
+	case " ", "x":
+		if len(m.items) > 0 {
+			name := m.items[m.cursor].Agent.Name
+			m.selected[name] = !m.selected[name]
+		}
+	case "a":
+		all := len(m.chosenNames()) == len(m.items) && len(m.selected) > 0
+		for _, item := range m.items {
+			m.selected[item.Agent.Name] = !all
+		}
+	case "s", "tab":
+		if m.scope == config.UserScope {
+			m.scope = config.ProjectScope
+		} else {
+			m.scope = config.UserScope
+		}
+	case "enter", "i":
+		if names := m.chosen(); len(names) > 0 {
+			m.selection = Selection{Action: ActionInstall, Agents: names, Scope: m.scope}
+			return m, tea.Quit
+		}
+	case "d":
+		names := m.installedChosen()
+		if len(names) == 0 {
+			m.message = fmt.Sprintf("None of the chosen agents are installed in %s scope", m.scope)
+			return m, nil
+		}
+		m.confirm = true
+		m.message = fmt.Sprintf("Remove %s from %s scope? (y/n)", strings.Join(names, ", "), m.scope)
+	case "pgdown", "ctrl+d":
+		m.offset += m.bodyHeight() / 2
+	case "pgup", "ctrl+u":
+		m.offset = max(0, m.offset-m.bodyHeight()/2)
+	}
+	return m, nil
+}
+
+// chosenNames returns the checked agents in list order
+func (m agentModel) chosenNames() []string {
+	var names []string
+	for _, item := range m.items {
+		if m.selected[item.Agent.Name] {
+			names = append(names, item.Agent.Name)
+		}
+	}
+	return names
+}
+
+// chosen returns the checked agents, or the agent under the cursor if none are
+func (m agentModel) chosen() []string {
+	if names := m.chosenNames(); len(names) > 0 {
+		return names
+	}
+	if len(m.items) == 0 {
+		return nil
+	}
+	return []string{m.items[m.cursor].Agent.Name}
+}
+
+// installedChosen returns the chosen agents that are installed in the current scope
+func (m agentModel) installedChosen() []string {
+	chosen := make(map[string]bool)
+	for _, name := range m.chosen() {
+		chosen[name] = true
+	}
+
+	var names []string
+	for _, item := range m.items {
+		if _, ok := item.Status[m.scope]; ok && chosen[item.Agent.Name] {
+			names = append(names, item.Agent.Name)
+		}
+	}
+	return names
+}
+
+// bodyHeight is the number of lines available to the list and detail panes
+func (m agentModel) bodyHeight() int {
+	return max(3, m.height-4)
+}
+
+func (m agentModel) View() string {
+	header := titleStyle.Render("OpenCode Agents") + "  " + faintStyle.Render("scope: ") + string(m.scope)
+
+	list := m.listView()
+	listWidth := lipgloss.Width(list)
+	detail := m.detailView(max(20, m.width-listWidth-3))
+	body := lipgloss.JoinHorizontal(lipgloss.Top, list, "   ", detail)
+
+	help := "↑/↓ move • space select • a all • s scope • enter install • d uninstall • pgup/pgdn scroll • q quit"
+	footer := faintStyle.Render(help)
+	if m.message != "" {
+		footer = warnStyle.Render(m.message)
+	}
+
+	return header + "\n\n" + body + "\n" + footer
+}
+
+// listView renders the agents with their checkboxes and install status
+func (m agentModel) listView() string {
+	nameWidth := len("Agent")
+	for _, item := range m.items {
+		nameWidth = max(nameWidth, len(item.Agent.Name))
+	}
+
+	lines := []string{faintStyle.Render(fmt.Sprintf("      %-*s  user  project", nameWidth, "Agent"))}
+	if len(m.items) == 0 {
+		lines = append(lines, "  No agents available.")
+	}
+
+	// Keep the cursor visible when the list is taller than the screen
+	visible := m.bodyHeight() - 1
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+
+	for i := start; i < len(m.items) && i < start+visible; i++ {
+		item := m.items[i]
+		pointer := "  "
+		if i == m.cursor {
+			pointer = "> "
+		}
+		box := "[ ]"
+		if m.selected[item.Agent.Name] {
+			box = "[x]"
+		}
+
+		line := fmt.Sprintf("%s%s %-*s  %s     %s", pointer, box, nameWidth, item.Agent.Name,
+			statusMark(item.Status[config.UserScope]), statusMark(item.Status[config.ProjectScope]))
+		if i == m.cursor {
+			line = cursorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// detailView renders the agent under the cursor, scrolled by offset
+func (m agentModel) detailView(width int) string {
+	if len(m.items) == 0 {
+		return ""
+	}
+	a := m.items[m.cursor].Agent
+
+	model := a.Model
+	if model == "" {
+		model = "default model"
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(a.Name) + "\n")
+	b.WriteString(a.Description + "\n")
+	b.WriteString(faintStyle.Render(fmt.Sprintf("%s · %s · temperature %.1f", a.Mode, model, a.Temperature)) + "\n\n")
+	b.WriteString(renderMarkdown(a.Content))
+
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(b.String()), "\n")
+	height := m.bodyHeight()
+	offset := min(m.offset, max(0, len(lines)-height))
+	end := min(len(lines), offset+height)
+
+	return strings.Join(lines[offset:end], "\n")
+}
+
+// statusMark is the symbol shown for an agent's install status in a scope
+func statusMark(status string) string {
+	switch status {
+	case config.StatusInstalled:
+		return okStyle.Render("✓")
+	case config.StatusModified:
+		return warnStyle.Render("⚠")
+	case config.StatusMissing:
+		return errStyle.Render("✗")
+	case config.StatusUnmanaged:
+		return "•"
+	default:
+		return faintStyle.Render("·")
+	}
+}
+
+// renderMarkdown highlights headings and dims fenced code in agent content
+func renderMarkdown(content string) string {
+	var lines []string
+	inFence := false
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			inFence = !inFence
+			line = faintStyle.Render(line)
+		case inFence:
+			line = faintStyle.Render(line)
+		case strings.HasPrefix(line, "#"):
+			line = headingStyle.Render(strings.TrimLeft(line, "# "))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gsmlg-dev/open-code-agents/pkg/config"
+	"github.com/gsmlg-dev/open-code-agents/pkg/resources"
+)
+
+func testItems() []AgentItem {
+	return []AgentItem{
+		{Agent: resources.AgentResource{Name: "architect", Content: "# Architect\n\n## Role\nDesigns systems."}},
+		{Agent: resources.AgentResource{Name: "reviewer"}, Status: map[config.Scope]string{config.UserScope: config.StatusInstalled}},
+		{Agent: resources.AgentResource{Name: "tester"}, Status: map[config.Scope]string{config.ProjectScope: config.StatusModified}},
+	}
+}
+
+// press sends key presses to a model and returns the updated model and the
+// command returned by the last key
+func press(m tea.Model, keys ...string) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m, cmd = m.Update(msg)
+	}
+	return m, cmd
+}
+
+func TestAgentModel_Install(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		want      []string
+		wantScope config.Scope
+	}{
+		{"cursor agent", []string{"down", "enter"}, []string{"reviewer"}, config.UserScope},
+		{"checked agents", []string{" ", "down", "down", " ", "enter"}, []string{"architect", "tester"}, config.UserScope},
+		{"all agents in project scope", []string{"a", "s", "i"}, []string{"architect", "reviewer", "tester"}, config.ProjectScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := press(newAgentModel(testItems(), config.UserScope), tt.keys...)
+			if cmd == nil {
+				t.Fatalf("install did not quit the manager")
+			}
+
+			sel := m.(agentModel).selection
+			if sel.Action != ActionInstall || !reflect.DeepEqual(sel.Agents, tt.want) || sel.Scope != tt.wantScope {
+				t.Errorf("selection = %+v, want install %v to %s", sel, tt.want, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestAgentModel_Uninstall(t *testing.T) {
+	// Only agents installed in the current scope are removed, after confirmation
+	m, _ := press(newAgentModel(testItems(), config.UserScope), "a", "d")
+	if !m.(agentModel).confirm || !strings.Contains(m.View(), "Remove reviewer from user scope?") {
+		t.Fatalf("uninstall did not ask for confirmation, view:\n%s", m.View())
+	}
+
+	m, cmd := press(m, "y")
+	sel := m.(agentModel).selection
+	if cmd == nil || sel.Action != ActionUninstall || !reflect.DeepEqual(sel.Agents, []string{"reviewer"}) {
+		t.Errorf("selection = %+v, want uninstall [reviewer]", sel)
+	}
+
+	// Declining keeps the manager open
+	m, _ = press(newAgentModel(testItems(), config.UserScope), "down", "d", "n")
+	if sel := m.(agentModel).selection; sel.Action != ActionNone {
+		t.Errorf("declined uninstall selection = %+v, want none", sel)
+	}
+
+	// Nothing to remove when the agent is not installed in the scope
+	m, _ = press(newAgentModel(testItems(), config.ProjectScope), "d")
+	if m.(agentModel).confirm {
+		t.Errorf("asked to uninstall an agent that is not installed")
+	}
+}
+
+func TestAgentModel_View(t *testing.T) {
+	view := newAgentModel(testItems(), config.UserScope).View()
+
+	for _, want := range []string{"scope: user", "architect", "reviewer", "tester", "Designs systems."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package tui provides the full-screen terminal interfaces used when
+// opencode-setup runs in a terminal
+package tui
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Screen size assumed until the terminal reports its size
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	headingStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	cursorStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("14"))
+	faintStyle   = lipgloss.NewStyle().Faint(true)
+	okStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	warnStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	errStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	paneStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+)
+
+// Available reports whether stdin and stdout are terminals, so that a
+// full-screen interface can be shown instead of line-based prompts
+func Available() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// newProgram creates a full-screen program. Its output is created without
+// termenv's color cache, which queries the terminal's colors on startup and
+// stalls for seconds on terminals that do not answer.
+func newProgram(m tea.Model) *tea.Program {
+	return tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(termenv.NewOutput(os.Stdout)))
+}
@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gsmlg-dev/open-code-agents/pkg/orchestrator"
+)
+
+// spinnerFrames animate the running step
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// RunWorkflow executes a workflow while showing each step's status, output
+// and token counts, and returns the workflow result once the user exits
+func RunWorkflow(ctx context.Context, orch *orchestrator.Orchestrator, workflow orchestrator.Workflow) (*orchestrator.WorkflowResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := newProgram(newWorkflowModel(workflow, cancel))
+	orch.Events = func(e orchestrator.Event) {
+		p.Send(eventMsg(e))
+	}
+
+	go func() {
+		result, err := orch.ExecuteWorkflow(ctx, workflow)
+		p.Send(doneMsg{result: result, err: err})
+	}()
+
+	final, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run workflow view: %w", err)
+	}
+	m := final.(workflowModel)
+	return m.result, m.err
+}
+
+// eventMsg delivers orchestrator progress to the view
+type eventMsg orchestrator.Event
+
+// doneMsg is sent when the workflow has finished
+type doneMsg struct {
+	result *orchestrator.WorkflowResult
+	err    error
+}
+
+// tickMsg advances the spinner
+type tickMsg struct{}
+
+// stepView is the displayed state of one workflow step
+type stepView struct {
+	agent        string
+	status       orchestrator.StepStatus
+	output       string
+	err          string
+	inputTokens  int
+	outputTokens int
+}
+
+// workflowModel is the state of the workflow view
+type workflowModel struct {
+	name     string
+	steps    []stepView
+	current  int  // Step whose output is shown
+	follow   bool // Show the output of the running step
+	frame    int
+	done     bool
+	quitting bool // Quit requested while the workflow was running
+	result   *orchestrator.WorkflowResult
+	err      error
+	cancel   context.CancelFunc
+	width    int
+	height   int
+}
+
+func newWorkflowModel(workflow orchestrator.Workflow, cancel context.CancelFunc) workflowModel {
+	steps := make([]stepView, len(workflow.Steps))
+	for i, step := range workflow.Steps {
+		steps[i] = stepView{agent: step.AgentName, status: orchestrator.StepPending}
+	}
+	return workflowModel{
+		name:   workflow.Name,
+		steps:  steps,
+		follow: true,
+		cancel: cancel,
+		width:  defaultWidth,
+		height: defaultHeight,
+	}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+func (m workflowModel) Init() tea.Cmd {
+	return tick()
+}
+
+func (m workflowModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tickMsg:
+		if !m.done {
+			m.frame++
+			return m, tick()
+		}
+	case eventMsg:
+		if msg.Step < 0 || msg.Step >= len(m.steps) {
+			return m, nil
+		}
+		step := &m.steps[msg.Step]
+		step.status = msg.Status
+		step.output += msg.Output
+		if msg.Result != nil {
+			step.err = msg.Result.Error
+			if msg.Result.Usage != nil {
+				step.inputTokens = msg.Result.Usage.InputTokens
+				step.outputTokens = msg.Result.Usage.OutputTokens
+			}
+		}
+		if m.follow {
+			m.current = msg.Step
+		}
+	case doneMsg:
+		m.done = true
+		m.result, m.err = msg.result, msg.err
+		if m.quitting {
+			return m, tea.Quit
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			if m.done {
+				return m, tea.Quit
+			}
+			// Stop after the running step and exit once the workflow returns
+			m.quitting = true
+			m.cancel()
+		case "up", "k":
+			if m.current > 0 {
+				m.current--
+				m.follow = false
+			}
+		case "down", "j":
+			if m.current < len(m.steps)-1 {
+				m.current++
+				m.follow = false
+			}
+		case "f":
+			m.follow = true
+		}
+	}
+	return m, nil
+}
+
+func (m workflowModel) View() string {
+	var b strings.Builder
+
+	state := spinnerFrames[m.frame%len(spinnerFrames)] + " running"
+	switch {
+	case m.done && m.err != nil:
+		state = errStyle.Render("✗ " + m.err.Error())
+	case m.done && m.result != nil && m.result.Success:
+		state = okStyle.Render("✓ completed")
+	case m.done:
+		state = errStyle.Render("✗ failed")
+	case m.quitting:
+		state = warnStyle.Render("stopping…")
+	}
+	b.WriteString(titleStyle.Render("Workflow: "+m.name) + "  " + state + "\n\n")
+
+	totalIn, totalOut := 0, 0
+	for i, step := range m.steps {
+		pointer := "  "
+		if i == m.current {
+			pointer = "> "
+		}
+
+		tokens := ""
+		if step.inputTokens > 0 || step.outputTokens > 0 {
+			tokens = faintStyle.Render(fmt.Sprintf("  %d in · %d out tokens", step.inputTokens, step.outputTokens))
+		}
+		totalIn += step.inputTokens
+		totalOut += step.outputTokens
+
+		line := fmt.Sprintf("%s%s %d. %s", pointer, m.stepMark(step.status), i+1, step.agent)
+		if i == m.current {
+			line = cursorStyle.Render(line)
+		}
+		b.WriteString(line + tokens + "\n")
+	}
+	b.WriteString(faintStyle.Render(fmt.Sprintf("\nTotal: %d in · %d out tokens", totalIn, totalOut)) + "\n")
+
+	if m.done && m.result != nil && m.result.Error != "" {
+		b.WriteString(errStyle.Render(m.result.Error) + "\n")
+	}
+
+	b.WriteString(m.outputView(m.height-lipgloss.Height(b.String())) + "\n")
+
+	help := "↑/↓ select step • f follow running step • q stop"
+	if m.done {
+		help = "↑/↓ select step • q exit"
+	}
+	b.WriteString(faintStyle.Render(help))
+
+	return b.String()
+}
+
+// stepMark is the symbol shown for a step's status
+func (m workflowModel) stepMark(status orchestrator.StepStatus) string {
+	switch status {
+	case orchestrator.StepRunning:
+		return spinnerFrames[m.frame%len(spinnerFrames)]
+	case orchestrator.StepSucceeded:
+		return okStyle.Render("✓")
+	case orchestrator.StepFailed:
+		return errStyle.Render("✗")
+	default:
+		return faintStyle.Render("○")
+	}
+}
+
+// outputView renders the tail of the selected step's output in a pane of
+// the given height
+func (m workflowModel) outputView(height int) string {
+	if len(m.steps) == 0 {
+		return ""
+	}
+	step := m.steps[m.current]
+
+	width := max(20, m.width-4)
+	output := step.output
+	if step.err != "" {
+		output += errStyle.Render(step.err)
+	}
+	if output == "" {
+		output = faintStyle.Render("No output yet.")
+	}
+
+	// Show the newest lines while output streams in, leaving room for the
+	// title and the pane border
+	lines := strings.Split(lipgloss.NewStyle().Width(width-2).Render(strings.TrimRight(output, "\n")), "\n")
+	height = max(1, height-3)
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+
+	title := faintStyle.Render(fmt.Sprintf("Output: step %d (%s)", m.current+1, step.agent))
+	return title + "\n" + paneStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
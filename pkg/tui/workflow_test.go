@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gsmlg-dev/open-code-agents/pkg/agent"
+	"github.com/gsmlg-dev/open-code-agents/pkg/orchestrator"
+)
+
+func TestWorkflowModel_Events(t *testing.T) {
+	workflow := orchestrator.Workflow{
+		Name: "bug-fix",
+		Steps: []orchestrator.WorkflowStep{
+			{AgentName: "debugger"},
+			{AgentName: "implementer"},
+		},
+	}
+	cancelled := false
+	var m tea.Model = newWorkflowModel(workflow, func() { cancelled = true })
+
+	send := func(msg tea.Msg) {
+		m, _ = m.Update(msg)
+	}
+
+	send(eventMsg{Step: 0, Status: orchestrator.StepRunning})
+	send(eventMsg{Step: 0, Status: orchestrator.StepRunning, Output: "found the cause\n"})
+	send(eventMsg{Step: 0, Status: orchestrator.StepSucceeded, Result: &orchestrator.StepResult{
+		Success: true,
+		Usage:   &agent.Usage{InputTokens: 120, OutputTokens: 8},
+	}})
+	send(eventMsg{Step: 1, Status: orchestrator.StepRunning, Output: "patching\n"})
+
+	wm := m.(workflowModel)
+	if wm.current != 1 {
+		t.Errorf("current step = %d, want the running step 1", wm.current)
+	}
+	view := wm.View()
+	for _, want := range []string{"120 in · 8 out tokens", "patching", "Total: 120 in · 8 out tokens"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// Selecting an earlier step shows its output and stops following
+	m, _ = press(m, "k")
+	send(eventMsg{Step: 1, Status: orchestrator.StepRunning, Output: "more\n"})
+	if view := m.(workflowModel).View(); !strings.Contains(view, "found the cause") {
+		t.Errorf("selected step output not shown:\n%s", view)
+	}
+
+	// Quitting while running cancels and waits for the workflow to return
+	m, cmd := press(m, "q")
+	if !cancelled || cmd != nil {
+		t.Errorf("quit while running: cancelled = %v, cmd = %v, want cancel without exiting", cancelled, cmd)
+	}
+	m, cmd = m.Update(doneMsg{result: &orchestrator.WorkflowResult{Success: false}})
+	if cmd == nil || !m.(workflowModel).done {
+		t.Errorf("view did not exit after the workflow returned")
+	}
+}